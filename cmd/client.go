@@ -2,22 +2,117 @@ package cmd
 
 import (
 	"fmt"
+	"log/slog"
+	"net/http"
 	"os"
+	"path/filepath"
+	"strconv"
+	"time"
 
 	"github.com/thegreenwebfoundation/grid-intensity-go/pkg/provider"
+	"github.com/thegreenwebfoundation/grid-intensity-go/pkg/recorder"
 )
 
-func getClient(providerName string, cacheFile string) (provider.Interface, error) {
+// getHTTPClient returns a client that records or replays HTTP exchanges with
+// provider APIs. If neither dir is set nil is returned so providers use their
+// default client.
+func getHTTPClient(recordDir, replayDir string) (*http.Client, error) {
+	switch {
+	case recordDir != "" && replayDir != "":
+		return nil, fmt.Errorf("only one of %#q and %#q can be set", recordKey, replayKey)
+	case recordDir != "":
+		r, err := recorder.NewRecorder(recordDir, nil)
+		if err != nil {
+			return nil, fmt.Errorf("could not make recorder, %w", err)
+		}
+		return &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: r,
+		}, nil
+	case replayDir != "":
+		r, err := recorder.NewReplayer(replayDir)
+		if err != nil {
+			return nil, fmt.Errorf("could not make replayer, %w", err)
+		}
+		return &http.Client{
+			Transport: r,
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// isReplaying returns true if the client replays recorded HTTP exchanges.
+func isReplaying(httpClient *http.Client) bool {
+	if httpClient == nil {
+		return false
+	}
+
+	_, ok := httpClient.Transport.(*recorder.Replayer)
+	return ok
+}
+
+func getClient(providerName string, cacheFile string, httpClient *http.Client, logger *slog.Logger) (provider.Interface, error) {
 	var client provider.Interface
 	var err error
 
+	rateLimit := readRateLimitConfig(providerName)
+
 	switch providerName {
+	case provider.AEMO:
+		c := provider.AEMOConfig{
+			Client:    httpClient,
+			RateLimit: rateLimit,
+			Logger:    logger,
+		}
+		client, err = provider.NewAEMO(c)
+		if err != nil {
+			return nil, fmt.Errorf("could not make aemo provider, %w", err)
+		}
+	case provider.CarbonAwareSDK:
+		url := os.Getenv(carbonAwareSDKAPIURLEnvVar)
+		if url == "" {
+			return nil, fmt.Errorf("%q env var must be set", carbonAwareSDKAPIURLEnvVar)
+		}
+		c, err := readCarbonAwareSDKConfig()
+		if err != nil {
+			return nil, err
+		}
+		c.Client = httpClient
+		c.RateLimit = rateLimit
+		c.Logger = logger
+		c.APIURL = url
+
+		client, err = provider.NewCarbonAwareSDK(c)
+		if err != nil {
+			return nil, fmt.Errorf("could not make carbon aware sdk provider, %w", err)
+		}
 	case provider.CarbonIntensityOrgUK:
-		c := provider.CarbonIntensityUKConfig{}
+		c := provider.CarbonIntensityUKConfig{
+			Client:    httpClient,
+			RateLimit: rateLimit,
+			Logger:    logger,
+		}
 		client, err = provider.NewCarbonIntensityUK(c)
 		if err != nil {
 			return nil, fmt.Errorf("could not make carbon intensity uk provider, %w", err)
 		}
+	case provider.EIA:
+		apiKey := os.Getenv(eiaAPIKeyEnvVar)
+		if apiKey == "" {
+			return nil, fmt.Errorf("%q env var must be set", eiaAPIKeyEnvVar)
+		}
+
+		c := provider.EIAConfig{
+			Client:    httpClient,
+			RateLimit: rateLimit,
+			Logger:    logger,
+			APIKey:    apiKey,
+		}
+		client, err = provider.NewEIA(c)
+		if err != nil {
+			return nil, fmt.Errorf("could not make eia provider, %w", err)
+		}
 	case provider.ElectricityMaps:
 		token := os.Getenv(electricityMapAPITokenEnvVar)
 		if token == "" {
@@ -29,18 +124,110 @@ func getClient(providerName string, cacheFile string) (provider.Interface, error
 		}
 
 		c := provider.ElectricityMapsConfig{
-			APIURL: url,
-			Token:  token,
+			Client:    httpClient,
+			RateLimit: rateLimit,
+			Logger:    logger,
+			APIURL:    url,
+			Token:     token,
 		}
 		client, err = provider.NewElectricityMaps(c)
 		if err != nil {
 			return nil, fmt.Errorf("could not make electricity maps provider, %w", err)
 		}
 	case provider.Ember:
-		client, err = provider.NewEmber()
+		c := provider.EmberConfig{
+			DataFile:        os.Getenv(emberDataFileEnvVar),
+			MonthlyDataFile: os.Getenv(emberMonthlyDataFileEnvVar),
+		}
+		if year := os.Getenv(emberDataYearEnvVar); year != "" {
+			c.Year, err = strconv.Atoi(year)
+			if err != nil {
+				return nil, fmt.Errorf("%q env var must be a year, %w", emberDataYearEnvVar, err)
+			}
+		}
+		// Newer Ember data can be added to the cache dir.
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			c.CacheDir = filepath.Join(homeDir, cacheDir)
+		}
+
+		client, err = provider.NewEmberWithConfig(c)
 		if err != nil {
 			return nil, fmt.Errorf("could not make ember provider, %w", err)
 		}
+	case provider.EnergiDataService:
+		c := provider.EnergiDataServiceConfig{
+			Client:    httpClient,
+			RateLimit: rateLimit,
+			Logger:    logger,
+		}
+		client, err = provider.NewEnergiDataService(c)
+		if err != nil {
+			return nil, fmt.Errorf("could not make energi data service provider, %w", err)
+		}
+	case provider.ENTSOE:
+		token := os.Getenv(entsoeAPITokenEnvVar)
+		if token == "" {
+			return nil, fmt.Errorf("%q env var must be set", entsoeAPITokenEnvVar)
+		}
+
+		c := provider.ENTSOEConfig{
+			Client:    httpClient,
+			RateLimit: rateLimit,
+			Logger:    logger,
+			Token:     token,
+		}
+		client, err = provider.NewENTSOE(c)
+		if err != nil {
+			return nil, fmt.Errorf("could not make entsoe provider, %w", err)
+		}
+	case provider.File:
+		path := os.Getenv(fileProviderPathEnvVar)
+		if path == "" {
+			return nil, fmt.Errorf("%q env var must be set", fileProviderPathEnvVar)
+		}
+
+		c := provider.FileConfig{
+			Path:   path,
+			Logger: logger,
+		}
+		client, err = provider.NewFile(c)
+		if err != nil {
+			return nil, fmt.Errorf("could not make file provider, %w", err)
+		}
+	case provider.JSONAPI:
+		c, err := readJSONAPIConfig()
+		if err != nil {
+			return nil, fmt.Errorf("could not read config for %#q, %w", jsonAPIKey, err)
+		}
+		c.Client = httpClient
+		c.RateLimit = rateLimit
+		c.Logger = logger
+		client, err = provider.NewJSONAPI(c)
+		if err != nil {
+			return nil, fmt.Errorf("could not make json api provider, %w", err)
+		}
+	case provider.Prometheus:
+		c, err := readPrometheusConfig()
+		if err != nil {
+			return nil, fmt.Errorf("could not read config for %#q, %w", prometheusKey, err)
+		}
+		c.Client = httpClient
+		c.RateLimit = rateLimit
+		c.Logger = logger
+		client, err = provider.NewPrometheus(c)
+		if err != nil {
+			return nil, fmt.Errorf("could not make prometheus provider, %w", err)
+		}
+	case provider.RTE:
+		c := provider.RTEConfig{
+			Client:    httpClient,
+			RateLimit: rateLimit,
+			Logger:    logger,
+		}
+		client, err = provider.NewRTE(c)
+		if err != nil {
+			return nil, fmt.Errorf("could not make rte provider, %w", err)
+		}
 	case provider.WattTime:
 		user := os.Getenv(wattTimeUserEnvVar)
 		if user == "" {
@@ -52,17 +239,40 @@ func getClient(providerName string, cacheFile string) (provider.Interface, error
 			return nil, fmt.Errorf("%q env var must be set", wattTimePasswordEnvVar)
 		}
 
+		if isReplaying(httpClient) {
+			// Replayed tokens are redacted so the in-memory cache is used
+			// to avoid saving them to the cache file used by live runs.
+			cacheFile = ""
+		}
+
 		c := provider.WattTimeConfig{
+			Client:      httpClient,
+			RateLimit:   rateLimit,
+			Logger:      logger,
 			APIUser:     user,
 			APIPassword: password,
 			CacheFile:   cacheFile,
+			// Reuse the token between CLI runs.
+			CacheToken: true,
 		}
 		client, err = provider.NewWattTime(c)
 		if err != nil {
 			return nil, fmt.Errorf("could not make watt time provider, %w", err)
 		}
 	default:
-		return nil, fmt.Errorf("provider %q not supported", providerName)
+		// Other providers can be added by plugins on the PATH.
+		if _, err := provider.LookupPlugin(providerName); err != nil {
+			return nil, fmt.Errorf("provider %q not supported", providerName)
+		}
+
+		c := provider.PluginConfig{
+			Name:   providerName,
+			Logger: logger,
+		}
+		client, err = provider.NewPlugin(c)
+		if err != nil {
+			return nil, fmt.Errorf("could not make %s plugin provider, %w", providerName, err)
+		}
 	}
 
 	return client, nil
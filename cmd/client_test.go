@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/thegreenwebfoundation/grid-intensity-go/pkg/recorder"
+)
+
+func Test_IsReplaying(t *testing.T) {
+	replayClient := &http.Client{
+		Transport: &recorder.Replayer{},
+	}
+	if !isReplaying(replayClient) {
+		t.Errorf("expected replay client to be replaying")
+	}
+
+	recordClient, err := getHTTPClient(t.TempDir(), "")
+	if err != nil {
+		t.Fatalf("could not get record client: %s", err)
+	}
+	if isReplaying(recordClient) {
+		t.Errorf("expected record client not to be replaying")
+	}
+	if isReplaying(nil) {
+		t.Errorf("expected default client not to be replaying")
+	}
+}
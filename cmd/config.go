@@ -2,19 +2,218 @@ package cmd
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
+
+	"github.com/thegreenwebfoundation/grid-intensity-go/pkg/provider"
 )
 
 const (
-	electricityMapAPITokenEnvVar = "ELECTRICITY_MAPS_API_TOKEN"
-	electricityMapAPIURLEnvVar   = "ELECTRICITY_MAPS_API_URL"
-	wattTimeUserEnvVar           = "WATT_TIME_USER"
-	wattTimePasswordEnvVar       = "WATT_TIME_PASSWORD"
+	carbonAwareSDKAPIURLEnvVar          = "CARBON_AWARE_SDK_API_URL"
+	carbonAwareSDKDisableForecastEnvVar = "CARBON_AWARE_SDK_DISABLE_FORECAST"
+	carbonAwareSDKEmissionsTypeEnvVar   = "CARBON_AWARE_SDK_EMISSIONS_TYPE"
+	carbonAwareSDKKey                   = "carbon_aware_sdk"
+	eiaAPIKeyEnvVar                     = "EIA_API_KEY"
+	electricityMapAPITokenEnvVar        = "ELECTRICITY_MAPS_API_TOKEN"
+	electricityMapAPIURLEnvVar          = "ELECTRICITY_MAPS_API_URL"
+	emberDataFileEnvVar                 = "EMBER_DATA_FILE"
+	emberDataYearEnvVar                 = "EMBER_DATA_YEAR"
+	emberMonthlyDataFileEnvVar          = "EMBER_MONTHLY_DATA_FILE"
+	entsoeAPITokenEnvVar                = "ENTSOE_API_TOKEN"
+	fileProviderPathEnvVar              = "FILE_PROVIDER_PATH"
+	jsonAPIKey                          = "json_api"
+	prometheusKey                       = "prometheus"
+	rateLimitKey                        = "rate_limit"
+	wattTimeUserEnvVar                  = "WATT_TIME_USER"
+	wattTimePasswordEnvVar              = "WATT_TIME_PASSWORD"
 )
 
+// jsonAPIConfig is the config file format for the JSONAPI provider. Headers
+// and query params are lists of name value pairs because viper lowercases map
+// keys and query param names are case sensitive.
+type jsonAPIConfig struct {
+	Name          string      `mapstructure:"name"`
+	URLTemplate   string      `mapstructure:"url_template"`
+	Headers       []nameValue `mapstructure:"headers"`
+	QueryParams   []nameValue `mapstructure:"query_params"`
+	EmissionsType string      `mapstructure:"emissions_type"`
+	MetricType    string      `mapstructure:"metric_type"`
+	Units         string      `mapstructure:"units"`
+	TimeLayout    string      `mapstructure:"time_layout"`
+	Duration      string      `mapstructure:"duration"`
+	Mapping       struct {
+		Data        string `mapstructure:"data"`
+		Value       string `mapstructure:"value"`
+		ValidFrom   string `mapstructure:"valid_from"`
+		ValidTo     string `mapstructure:"valid_to"`
+		Units       string `mapstructure:"units"`
+		IsEstimated string `mapstructure:"is_estimated"`
+	} `mapstructure:"mapping"`
+}
+
+type nameValue struct {
+	Name  string `mapstructure:"name"`
+	Value string `mapstructure:"value"`
+}
+
+// expandNameValues returns the name value pairs as a map with environment
+// variables in the values expanded.
+func expandNameValues(pairs []nameValue) map[string]string {
+	result := map[string]string{}
+	for _, pair := range pairs {
+		result[pair.Name] = os.ExpandEnv(pair.Value)
+	}
+
+	return result
+}
+
+// readJSONAPIConfig reads the JSONAPI provider config from the config file.
+// Environment variables in headers and query params are expanded so secrets
+// don't need to be stored in the config file.
+func readJSONAPIConfig() (provider.JSONAPIConfig, error) {
+	config := jsonAPIConfig{}
+
+	err := viper.UnmarshalKey(jsonAPIKey, &config)
+	if err != nil {
+		return provider.JSONAPIConfig{}, err
+	}
+
+	var duration time.Duration
+	if config.Duration != "" {
+		duration, err = time.ParseDuration(config.Duration)
+		if err != nil {
+			return provider.JSONAPIConfig{}, fmt.Errorf("could not parse duration, %w", err)
+		}
+	}
+
+	return provider.JSONAPIConfig{
+		Name:          config.Name,
+		URLTemplate:   config.URLTemplate,
+		Headers:       expandNameValues(config.Headers),
+		QueryParams:   expandNameValues(config.QueryParams),
+		EmissionsType: config.EmissionsType,
+		MetricType:    config.MetricType,
+		Units:         config.Units,
+		TimeLayout:    config.TimeLayout,
+		Duration:      duration,
+		Mapping: provider.JSONAPIMapping{
+			Data:        config.Mapping.Data,
+			Value:       config.Mapping.Value,
+			ValidFrom:   config.Mapping.ValidFrom,
+			ValidTo:     config.Mapping.ValidTo,
+			Units:       config.Mapping.Units,
+			IsEstimated: config.Mapping.IsEstimated,
+		},
+	}, nil
+}
+
+// prometheusConfig is the config file format for the Prometheus provider.
+type prometheusConfig struct {
+	Name          string            `mapstructure:"name"`
+	APIURL        string            `mapstructure:"api_url"`
+	Query         string            `mapstructure:"query"`
+	Headers       map[string]string `mapstructure:"headers"`
+	Range         string            `mapstructure:"range"`
+	Step          string            `mapstructure:"step"`
+	EmissionsType string            `mapstructure:"emissions_type"`
+	MetricType    string            `mapstructure:"metric_type"`
+	Units         string            `mapstructure:"units"`
+}
+
+// readPrometheusConfig reads the Prometheus provider config from the config
+// file. Environment variables in headers are expanded.
+func readPrometheusConfig() (provider.PrometheusConfig, error) {
+	config := prometheusConfig{}
+
+	err := viper.UnmarshalKey(prometheusKey, &config)
+	if err != nil {
+		return provider.PrometheusConfig{}, err
+	}
+
+	var queryRange, step time.Duration
+	if config.Range != "" {
+		queryRange, err = time.ParseDuration(config.Range)
+		if err != nil {
+			return provider.PrometheusConfig{}, fmt.Errorf("could not parse range, %w", err)
+		}
+	}
+	if config.Step != "" {
+		step, err = time.ParseDuration(config.Step)
+		if err != nil {
+			return provider.PrometheusConfig{}, fmt.Errorf("could not parse step, %w", err)
+		}
+	}
+
+	headers := map[string]string{}
+	for key, value := range config.Headers {
+		headers[key] = os.ExpandEnv(value)
+	}
+
+	return provider.PrometheusConfig{
+		Name:          config.Name,
+		APIURL:        config.APIURL,
+		Query:         config.Query,
+		Headers:       headers,
+		Range:         queryRange,
+		Step:          step,
+		EmissionsType: config.EmissionsType,
+		MetricType:    config.MetricType,
+		Units:         config.Units,
+	}, nil
+}
+
+// readCarbonAwareSDKConfig reads the emissions type of the data source used
+// by the Carbon Aware SDK WebAPI and whether to disable the forecast from the
+// config file or the CARBON_AWARE_SDK_EMISSIONS_TYPE and
+// CARBON_AWARE_SDK_DISABLE_FORECAST env vars. An empty emissions type means
+// the provider default.
+func readCarbonAwareSDKConfig() (provider.CarbonAwareSDKConfig, error) {
+	emissionsTypeKey := carbonAwareSDKKey + ".emissions_type"
+	viper.BindEnv(emissionsTypeKey, carbonAwareSDKEmissionsTypeEnvVar)
+	disableForecastKey := carbonAwareSDKKey + ".disable_forecast"
+	viper.BindEnv(disableForecastKey, carbonAwareSDKDisableForecastEnvVar)
+
+	emissionsType := viper.GetString(emissionsTypeKey)
+	switch emissionsType {
+	case "", provider.AverageEmissionsType, provider.MarginalEmissionsType:
+	default:
+		return provider.CarbonAwareSDKConfig{}, fmt.Errorf("invalid carbon aware sdk emissions type %q, must be %s or %s",
+			emissionsType, provider.AverageEmissionsType, provider.MarginalEmissionsType)
+	}
+
+	return provider.CarbonAwareSDKConfig{
+		EmissionsType:   emissionsType,
+		DisableForecast: viper.GetBool(disableForecastKey),
+	}, nil
+}
+
+// readRateLimitConfig reads the rate limit for the provider from the config
+// file or environment variables e.g. GRID_INTENSITY_RATE_LIMIT_DAILY_QUOTA.
+// The requests made today are saved in the cache dir.
+func readRateLimitConfig(providerName string) provider.RateLimitConfig {
+	for _, key := range []string{"requests_per_minute", "burst", "daily_quota"} {
+		viper.BindEnv(rateLimitKey+"."+key, strings.ToUpper("grid_intensity_"+rateLimitKey+"_"+key))
+	}
+
+	config := provider.RateLimitConfig{
+		RequestsPerMinute: viper.GetFloat64(rateLimitKey + ".requests_per_minute"),
+		Burst:             viper.GetInt(rateLimitKey + ".burst"),
+		DailyQuota:        viper.GetInt(rateLimitKey + ".daily_quota"),
+	}
+	if config.DailyQuota > 0 {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			config.QuotaFile = filepath.Join(homeDir, cacheDir, providerName+"-quota.json")
+		}
+	}
+
+	return config
+}
+
 func getConfigFile() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
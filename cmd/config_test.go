@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/viper"
+)
+
+var MockJSONAPIConfig = `
+json_api:
+  name: Internal
+  url_template: https://api.example.com/intensity/{location}
+  headers:
+    - name: X-Api-Key
+      value: ${TEST_API_KEY}
+  query_params:
+    - name: apiKey
+      value: ${TEST_API_KEY}
+    - name: regionCode
+      value: GB
+`
+
+func Test_ReadJSONAPIConfig(t *testing.T) {
+	t.Setenv("TEST_API_KEY", "secret")
+
+	viper.Reset()
+	defer viper.Reset()
+
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(MockJSONAPIConfig))
+	if err != nil {
+		t.Fatalf("could not read config: %s", err)
+	}
+
+	config, err := readJSONAPIConfig()
+	if err != nil {
+		t.Fatalf("error == %#v want nil", err)
+	}
+
+	// The case of names is kept.
+	expectedHeaders := map[string]string{
+		"X-Api-Key": "secret",
+	}
+	if !reflect.DeepEqual(expectedHeaders, config.Headers) {
+		t.Errorf("want matching \n %s", cmp.Diff(config.Headers, expectedHeaders))
+	}
+	expectedQueryParams := map[string]string{
+		"apiKey":     "secret",
+		"regionCode": "GB",
+	}
+	if !reflect.DeepEqual(expectedQueryParams, config.QueryParams) {
+		t.Errorf("want matching \n %s", cmp.Diff(config.QueryParams, expectedQueryParams))
+	}
+}
+
+func Test_ReadCarbonAwareSDKConfig(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	viper.SetConfigType("yaml")
+	err := viper.ReadConfig(strings.NewReader(`
+carbon_aware_sdk:
+  emissions_type: average
+`))
+	if err != nil {
+		t.Fatalf("could not read config: %s", err)
+	}
+
+	// The forecast is disabled with an env var.
+	t.Setenv(carbonAwareSDKDisableForecastEnvVar, "true")
+
+	config, err := readCarbonAwareSDKConfig()
+	if err != nil {
+		t.Fatalf("error == %#v want nil", err)
+	}
+	if config.EmissionsType != "average" || !config.DisableForecast {
+		t.Errorf("unexpected config %#v", config)
+	}
+
+	t.Setenv(carbonAwareSDKEmissionsTypeEnvVar, "unknown")
+	_, err = readCarbonAwareSDKConfig()
+	if err == nil {
+		t.Errorf("expected error for invalid emissions type")
+	}
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
+	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -33,6 +36,8 @@ func init() {
 	exporterCmd.Flags().StringP(nodeKey, "n", "", "Node where the exporter is running")
 	exporterCmd.Flags().StringP(providerKey, "p", provider.Ember, "Provider of carbon intensity data")
 	exporterCmd.Flags().StringP(regionKey, "r", "", "Region where the exporter is running")
+	exporterCmd.Flags().Int(parallelismKey, provider.DefaultParallelism, "Maximum number of locations to get at once")
+	addRecordFlags(exporterCmd)
 
 	// Also support environment variables.
 	viper.SetEnvPrefix("grid_intensity")
@@ -87,6 +92,17 @@ var (
 		nil,
 	)
 
+	quotaRemainingDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "quota", "remaining"),
+		"Requests remaining today in the daily quota for the provider.",
+		[]string{
+			labelNode,
+			labelProvider,
+			labelRegion,
+		},
+		nil,
+	)
+
 	exporterCmd = &cobra.Command{
 		Use:   "exporter",
 		Short: "Metrics for carbon intensity data for electricity grids",
@@ -105,27 +121,51 @@ the grid is greener or at locations where carbon intensity is lower.
 			viper.BindPFlag(regionKey, cmd.Flags().Lookup(regionKey))
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			err := runExporter()
+			logger, err := getLoggerFromFlags(cmd)
 			if err != nil {
 				log.Fatal(err)
 			}
+			httpClient, err := getHTTPClientFromFlags(cmd)
+			if err != nil {
+				logger.Error(err.Error())
+				os.Exit(1)
+			}
+			parallelism, err := cmd.Flags().GetInt(parallelismKey)
+			if err != nil {
+				logger.Error(err.Error())
+				os.Exit(1)
+			}
+			err = runExporter(httpClient, logger, parallelism)
+			if err != nil {
+				logger.Error(err.Error())
+				os.Exit(exitCode(err))
+			}
 		},
 	}
 )
 
 type Exporter struct {
-	client   provider.Interface
-	location string
-	node     string
-	provider string
-	region   string
+	client      provider.Interface
+	location    string
+	logger      *slog.Logger
+	node        string
+	parallelism int
+	provider    string
+	region      string
 }
 
 type ExporterConfig struct {
-	Location string
-	Node     string
-	Provider string
-	Region   string
+	// HTTPClient is optional and is used by providers that make requests.
+	HTTPClient *http.Client
+	Location   string
+	// Logger is optional and defaults to slog.Default.
+	Logger *slog.Logger
+	Node   string
+	// Parallelism is the maximum number of locations to get at once.
+	// Defaults to provider.DefaultParallelism.
+	Parallelism int
+	Provider    string
+	Region      string
 }
 
 func NewExporter(config ExporterConfig) (*Exporter, error) {
@@ -136,18 +176,24 @@ func NewExporter(config ExporterConfig) (*Exporter, error) {
 		return nil, fmt.Errorf("location must be set")
 	}
 
+	if config.Logger == nil {
+		config.Logger = slog.Default()
+	}
+
 	// Cache filename is empty so we use in-memory cache.
-	client, err = getClient(config.Provider, "")
+	client, err = getClient(config.Provider, "", config.HTTPClient, config.Logger)
 	if err != nil {
 		return nil, err
 	}
 
 	e := &Exporter{
-		client:   client,
-		location: config.Location,
-		node:     config.Node,
-		provider: config.Provider,
-		region:   config.Region,
+		client:      client,
+		location:    config.Location,
+		logger:      config.Logger,
+		node:        config.Node,
+		parallelism: config.Parallelism,
+		provider:    config.Provider,
+		region:      config.Region,
 	}
 
 	return e, nil
@@ -159,18 +205,37 @@ func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
 	var result []provider.CarbonIntensity
 	locationCodes := strings.Split(e.location, ",")
 
+	results, errs := provider.GetCarbonIntensityMulti(ctx, e.client, locationCodes, e.parallelism)
+
 	for _, locationCode := range locationCodes {
-		res, err := e.client.GetCarbonIntensity(ctx, locationCode)
-		if err != nil {
-			log.Printf("could not get carbon intensity for location %s, %#v", locationCode, err)
+		if err, ok := errs[locationCode]; ok {
+			e.logger.ErrorContext(ctx, "could not get carbon intensity",
+				"location", locationCode,
+				"error", err)
+			continue
 		}
-		result = append(result, res...)
+		result = append(result, results[locationCode]...)
 	}
 
-	for _, data := range result {
+	if reporter, ok := e.client.(provider.QuotaReporter); ok {
+		if remaining, ok := reporter.RemainingQuota(); ok {
+			ch <- prometheus.MustNewConstMetric(
+				quotaRemainingDesc,
+				prometheus.GaugeValue,
+				float64(remaining),
+				e.node,
+				e.provider,
+				e.region,
+			)
+		}
+	}
+
+	for _, data := range latestValues(result, time.Now()) {
 		desc, err := getMetricDesc(data)
 		if err != nil {
-			log.Printf("failed to get metric description %#v", err)
+			e.logger.WarnContext(ctx, "could not get metric description",
+				"location", data.Location,
+				"error", err)
 			continue
 		}
 
@@ -188,6 +253,51 @@ func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
 	}
 }
 
+// metricKey identifies the values exported with the same metric and labels.
+type metricKey struct {
+	emissionsType string
+	metricType    string
+	location      string
+	provider      string
+	units         string
+	isEstimated   bool
+}
+
+// latestValues returns the latest value for each metric and set of labels.
+// Forecast values are skipped as only the current value is exported. Providers
+// such as Prometheus with a range return several values per location which
+// would otherwise be duplicate metrics.
+func latestValues(result []provider.CarbonIntensity, now time.Time) []provider.CarbonIntensity {
+	index := map[metricKey]int{}
+	var latest []provider.CarbonIntensity
+
+	for _, data := range result {
+		if data.ValidFrom.After(now) {
+			continue
+		}
+
+		key := metricKey{
+			emissionsType: data.EmissionsType,
+			metricType:    data.MetricType,
+			location:      data.Location,
+			provider:      data.Provider,
+			units:         data.Units,
+			isEstimated:   data.IsEstimated,
+		}
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(latest)
+			latest = append(latest, data)
+			continue
+		}
+		if data.ValidFrom.After(latest[i].ValidFrom) {
+			latest[i] = data
+		}
+	}
+
+	return latest
+}
+
 func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
 	if e.provider == provider.WattTime {
 		ch <- marginalDesc
@@ -195,6 +305,12 @@ func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
 	} else {
 		ch <- averageDesc
 	}
+
+	if reporter, ok := e.client.(provider.QuotaReporter); ok {
+		if _, ok := reporter.RemainingQuota(); ok {
+			ch <- quotaRemainingDesc
+		}
+	}
 }
 
 func getMetricDesc(data provider.CarbonIntensity) (*prometheus.Desc, error) {
@@ -215,7 +331,7 @@ func getMetricDesc(data provider.CarbonIntensity) (*prometheus.Desc, error) {
 	return nil, fmt.Errorf("unknown metric type %s", data.MetricType)
 }
 
-func runExporter() error {
+func runExporter(httpClient *http.Client, logger *slog.Logger, parallelism int) error {
 	providerName, err := readConfig(providerKey)
 	if err != nil {
 		return err
@@ -234,10 +350,13 @@ func runExporter() error {
 	}
 
 	c := ExporterConfig{
-		Location: locationCode,
-		Node:     node,
-		Provider: providerName,
-		Region:   region,
+		HTTPClient:  httpClient,
+		Location:    locationCode,
+		Logger:      logger,
+		Node:        node,
+		Parallelism: parallelism,
+		Provider:    providerName,
+		Region:      region,
 	}
 	exporter, err := NewExporter(c)
 	if err != nil {
@@ -249,13 +368,13 @@ func runExporter() error {
 		return err
 	}
 
-	fmt.Printf("Using provider %q with location %q\n", providerName, locationCode)
-	fmt.Println("Metrics available at :8000/metrics")
+	logger.Info("metrics available at :8000/metrics",
+		"provider", providerName,
+		"location", locationCode)
 
 	prometheus.MustRegister(exporter)
 
 	http.Handle("/metrics", promhttp.Handler())
-	log.Fatalln(http.ListenAndServe(":8000", nil))
 
-	return nil
+	return http.ListenAndServe(":8000", nil)
 }
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/thegreenwebfoundation/grid-intensity-go/pkg/provider"
+)
+
+// staticProvider returns the same data for every location.
+type staticProvider struct {
+	data []provider.CarbonIntensity
+}
+
+func (s staticProvider) GetCarbonIntensity(ctx context.Context, location string) ([]provider.CarbonIntensity, error) {
+	return s.data, nil
+}
+
+func Test_Exporter_RangeValues(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Minute)
+
+	value := func(validFrom time.Time, value float64) provider.CarbonIntensity {
+		return provider.CarbonIntensity{
+			EmissionsType: provider.AverageEmissionsType,
+			MetricType:    provider.AbsoluteMetricType,
+			Provider:      provider.Prometheus,
+			Location:      "UK",
+			Units:         provider.GramsCO2EPerkWh,
+			ValidFrom:     validFrom,
+			ValidTo:       validFrom.Add(5 * time.Minute),
+			Value:         value,
+		}
+	}
+
+	// Several values for the same location as returned by a range query.
+	e := &Exporter{
+		client: staticProvider{
+			data: []provider.CarbonIntensity{
+				value(now.Add(-10*time.Minute), 180),
+				value(now.Add(-5*time.Minute), 190),
+				value(now.Add(-15*time.Minute), 170),
+				value(now.Add(5*time.Minute), 200),
+			},
+		},
+		location:    "UK",
+		logger:      slog.Default(),
+		parallelism: 1,
+		provider:    provider.Prometheus,
+	}
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(e)
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("could not gather metrics: %s", err)
+	}
+	if len(families) != 1 || len(families[0].GetMetric()) != 1 {
+		t.Fatalf("expected 1 metric got %v", families)
+	}
+
+	// Only the latest value that is not a forecast is exported.
+	if got := families[0].GetMetric()[0].GetGauge().GetValue(); got != 190 {
+		t.Errorf("expected value 190 got %v", got)
+	}
+}
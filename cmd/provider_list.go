@@ -38,6 +38,10 @@ func runProviderList() error {
 	for _, p := range providers {
 		tbl.AddRow(p.Name, p.URL)
 	}
+	for _, name := range provider.FindPlugins() {
+		path, _ := provider.LookupPlugin(name)
+		tbl.AddRow(name, path)
+	}
 
 	tbl.Print()
 
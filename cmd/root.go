@@ -4,11 +4,15 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"log/slog"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/Xuanwo/go-locale"
 	"github.com/spf13/cobra"
@@ -20,9 +24,17 @@ import (
 const (
 	cacheDir              = ".cache/grid-intensity"
 	configDir             = ".config/grid-intensity"
+	atKey                 = "at"
 	configFileName        = "config.yaml"
+	emissionsTypeKey      = "emissions-type"
 	locationKey           = "location"
+	logFormatKey          = "log-format"
+	logLevelKey           = "log-level"
+	metricTypeKey         = "metric-type"
+	parallelismKey        = "parallelism"
 	providerKey           = "provider"
+	recordKey             = "record"
+	replayKey             = "replay"
 	wattTimeCacheFileName = "watttime.org.json"
 )
 
@@ -36,17 +48,38 @@ This can be used to make your software carbon aware so it runs at times when the
 grid is greener or at locations where carbon intensity is lower.
 
 	grid-intensity --provider Ember --location ARG
-	grid-intensity -p Ember -l BOL`,
+	grid-intensity -p Ember -l BOL
+	grid-intensity -p Ember -l BOL --at 2021-06-01`,
 
 	PreRun: func(cmd *cobra.Command, args []string) {
 		viper.BindPFlag(locationKey, cmd.Flags().Lookup(locationKey))
 		viper.BindPFlag(providerKey, cmd.Flags().Lookup(providerKey))
 	},
 	Run: func(cmd *cobra.Command, args []string) {
-		err := runRoot()
+		logger, err := getLoggerFromFlags(cmd)
 		if err != nil {
 			log.Fatal(err)
 		}
+		httpClient, err := getHTTPClientFromFlags(cmd)
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+		parallelism, err := cmd.Flags().GetInt(parallelismKey)
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+		query, err := getQueryFromFlags(cmd)
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(1)
+		}
+		err = runRoot(httpClient, logger, parallelism, query)
+		if err != nil {
+			logger.Error(err.Error())
+			os.Exit(exitCode(err))
+		}
 	},
 }
 
@@ -57,9 +90,40 @@ func Execute() {
 	}
 }
 
+// exitCode returns the exit code for the category of a provider error so
+// scripts can handle failures. Other errors exit with 1.
+func exitCode(err error) int {
+	var providerErr *provider.Error
+	if !errors.As(err, &providerErr) {
+		return 1
+	}
+
+	switch providerErr.Category {
+	case provider.ErrorCategoryNotFound:
+		return 2
+	case provider.ErrorCategoryAuth:
+		return 3
+	case provider.ErrorCategoryRateLimited:
+		return 4
+	case provider.ErrorCategoryUpstream:
+		return 5
+	case provider.ErrorCategoryParse:
+		return 6
+	}
+
+	return 1
+}
+
 func init() {
 	rootCmd.Flags().StringP(locationKey, "l", "", "Location codes for provider, for multiple locations separate with a comma")
 	rootCmd.Flags().StringP(providerKey, "p", provider.Ember, "Provider of carbon intensity data")
+	rootCmd.Flags().Int(parallelismKey, provider.DefaultParallelism, "Maximum number of locations to get at once")
+	rootCmd.Flags().String(emissionsTypeKey, "", "Emissions type to return, one of average or marginal")
+	rootCmd.Flags().String(metricTypeKey, "", "Metric type to return, one of absolute or relative")
+	rootCmd.Flags().String(atKey, "", "Time to get data for as an RFC 3339 time or a date, defaults to now")
+	addRecordFlags(rootCmd)
+	rootCmd.PersistentFlags().String(logLevelKey, "info", "Log level, one of debug, info, warn or error")
+	rootCmd.PersistentFlags().String(logFormatKey, "text", "Log format, one of text or json")
 
 	// Also support environment variables.
 	viper.SetEnvPrefix("grid_intensity")
@@ -92,7 +156,113 @@ func getCountryCode() (string, error) {
 	return strings.TrimSpace(country), nil
 }
 
-func runRoot() error {
+// addRecordFlags adds the flags for recording and replaying HTTP exchanges
+// with provider APIs. These are not saved in the config file.
+func addRecordFlags(cmd *cobra.Command) {
+	cmd.Flags().String(recordKey, "", "Directory to record HTTP exchanges with the provider to, secrets are redacted")
+	cmd.Flags().String(replayKey, "", "Directory to replay recorded HTTP exchanges from instead of calling the provider")
+}
+
+func getHTTPClientFromFlags(cmd *cobra.Command) (*http.Client, error) {
+	recordDir, err := cmd.Flags().GetString(recordKey)
+	if err != nil {
+		return nil, err
+	}
+	replayDir, err := cmd.Flags().GetString(replayKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return getHTTPClient(recordDir, replayDir)
+}
+
+// getLogger returns a logger that writes to stderr with the level and format.
+func getLogger(level, format string) (*slog.Logger, error) {
+	var logLevel slog.Level
+	err := logLevel.UnmarshalText([]byte(level))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %#q %q, %w", logLevelKey, level, err)
+	}
+
+	opts := &slog.HandlerOptions{
+		Level: logLevel,
+	}
+
+	switch format {
+	case "text":
+		return slog.New(slog.NewTextHandler(os.Stderr, opts)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(os.Stderr, opts)), nil
+	}
+
+	return nil, fmt.Errorf("invalid %#q %q, must be text or json", logFormatKey, format)
+}
+
+func getLoggerFromFlags(cmd *cobra.Command) (*slog.Logger, error) {
+	level, err := cmd.Flags().GetString(logLevelKey)
+	if err != nil {
+		return nil, err
+	}
+	format, err := cmd.Flags().GetString(logFormatKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return getLogger(level, format)
+}
+
+// getQueryFromFlags returns the query options from the flags. The location is
+// set for each location code.
+func getQueryFromFlags(cmd *cobra.Command) (provider.Query, error) {
+	emissionsType, err := cmd.Flags().GetString(emissionsTypeKey)
+	if err != nil {
+		return provider.Query{}, err
+	}
+	metricType, err := cmd.Flags().GetString(metricTypeKey)
+	if err != nil {
+		return provider.Query{}, err
+	}
+	at, err := cmd.Flags().GetString(atKey)
+	if err != nil {
+		return provider.Query{}, err
+	}
+
+	query := provider.Query{
+		EmissionsType: emissionsType,
+		MetricType:    metricType,
+	}
+	if at != "" {
+		query.At, err = parseTime(at)
+		if err != nil {
+			return provider.Query{}, fmt.Errorf("invalid %#q %q, %w", atKey, at, err)
+		}
+	}
+
+	return query, nil
+}
+
+// withProviderName sets the provider of the *Error in err if it is not set,
+// e.g. when a provider returns no data for a query.
+func withProviderName(providerName string, err error) error {
+	var providerErr *provider.Error
+	if errors.As(err, &providerErr) && providerErr.Provider == "" {
+		providerErr.Provider = providerName
+	}
+
+	return err
+}
+
+// parseTime parses an RFC 3339 time or a date which is midnight UTC.
+func parseTime(value string) (time.Time, error) {
+	t, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return t, nil
+	}
+
+	return time.Parse(time.DateOnly, value)
+}
+
+func runRoot(httpClient *http.Client, logger *slog.Logger, parallelism int, query provider.Query) error {
 	ctx := context.Background()
 
 	providerName, err := readConfig(providerKey)
@@ -134,18 +304,41 @@ func runRoot() error {
 		cacheFile = filepath.Join(homeDir, cacheDir, wattTimeCacheFileName)
 	}
 
-	client, err := getClient(providerName, cacheFile)
+	client, err := getClient(providerName, cacheFile, httpClient, logger)
 	if err != nil {
 		return fmt.Errorf("could not get client, %w", err)
 	}
 
+	var results map[string][]provider.CarbonIntensity
+	var errs map[string]error
+	if query == (provider.Query{}) {
+		results, errs = provider.GetCarbonIntensityMulti(ctx, client, locationCodes, parallelism)
+	} else {
+		results, errs = provider.GetCarbonIntensityQueryMulti(ctx, client, query, locationCodes, parallelism)
+	}
+
 	var result []provider.CarbonIntensity
+	var failed []string
 	for _, locationCode := range locationCodes {
-		res, err := client.GetCarbonIntensity(ctx, locationCode)
-		if err != nil {
-			return fmt.Errorf("could not get carbon intensity for location %s, %w", locationCode, err)
+		if err, ok := errs[locationCode]; ok {
+			errs[locationCode] = withProviderName(providerName, err)
+			failed = append(failed, locationCode)
+			continue
+		}
+		result = append(result, results[locationCode]...)
+	}
+
+	var locationErr error
+	if len(failed) > 0 {
+		// The first error is returned so the exit code is set from its
+		// category and the others are logged.
+		for _, locationCode := range failed[1:] {
+			logger.Error("could not get carbon intensity", "location", locationCode, "error", errs[locationCode])
+		}
+		locationErr = fmt.Errorf("could not get carbon intensity for location %s, %w", failed[0], errs[failed[0]])
+		if len(result) == 0 {
+			return locationErr
 		}
-		result = append(result, res...)
 	}
 
 	bytes, err := json.MarshalIndent(result, "", "\t")
@@ -154,6 +347,10 @@ func runRoot() error {
 	}
 	fmt.Println(string(bytes))
 
+	if locationErr != nil {
+		return locationErr
+	}
+
 	err = writeConfig()
 	if err != nil {
 		return fmt.Errorf("could not write config, %w", err)
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/thegreenwebfoundation/grid-intensity-go/pkg/provider"
+)
+
+// emptyProvider returns no data for every location.
+type emptyProvider struct{}
+
+func (emptyProvider) GetCarbonIntensity(ctx context.Context, location string) ([]provider.CarbonIntensity, error) {
+	return nil, nil
+}
+
+func Test_WithProviderName(t *testing.T) {
+	query := provider.Query{
+		Location: "CAISO_NORTH",
+		At:       time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+	_, err := provider.GetCarbonIntensityQuery(context.Background(), emptyProvider{}, query)
+
+	// The provider is not known from the results when there is no data.
+	err = withProviderName(provider.WattTime, err)
+
+	var providerErr *provider.Error
+	if !errors.As(err, &providerErr) || providerErr.Provider != provider.WattTime || !errors.Is(err, provider.ErrNoIntensityPresentForTime) {
+		t.Errorf("expected no data error for %s got %#v", provider.WattTime, err)
+	}
+
+	// The provider of an error is kept.
+	err = withProviderName(provider.WattTime, &provider.Error{Provider: provider.Ember})
+	if !errors.As(err, &providerErr) || providerErr.Provider != provider.Ember {
+		t.Errorf("expected error for %s got %#v", provider.Ember, err)
+	}
+}
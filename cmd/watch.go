@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/thegreenwebfoundation/grid-intensity-go/pkg/provider"
+)
+
+const (
+	minIntervalKey = "min-interval"
+	maxIntervalKey = "max-interval"
+)
+
+func init() {
+	watchCmd.Flags().StringP(locationKey, "l", "", "Location codes for provider, for multiple locations separate with a comma")
+	watchCmd.Flags().StringP(providerKey, "p", provider.Ember, "Provider of carbon intensity data")
+	watchCmd.Flags().Duration(minIntervalKey, 0, "Minimum wait between requests, defaults to 1m")
+	watchCmd.Flags().Duration(maxIntervalKey, 0, "Maximum wait between requests, defaults to 1h")
+	addRecordFlags(watchCmd)
+
+	rootCmd.AddCommand(watchCmd)
+}
+
+var (
+	watchCmd = &cobra.Command{
+		Use:   "watch",
+		Short: "Print carbon intensity data for electricity grids when it changes",
+		Long: `Watch the carbon intensity data for electricity grids and print each
+update as it is published.
+
+Requests are made when the current value is no longer valid so this can be used
+by long running services to react to new values without polling.
+
+	grid-intensity watch --provider CarbonIntensityOrgUK --location UK
+	grid-intensity watch -p ElectricityMaps -l DE,FR`,
+		PreRun: func(cmd *cobra.Command, args []string) {
+			viper.BindPFlag(locationKey, cmd.Flags().Lookup(locationKey))
+			viper.BindPFlag(providerKey, cmd.Flags().Lookup(providerKey))
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			logger, err := getLoggerFromFlags(cmd)
+			if err != nil {
+				log.Fatal(err)
+			}
+			httpClient, err := getHTTPClientFromFlags(cmd)
+			if err != nil {
+				logger.Error(err.Error())
+				os.Exit(1)
+			}
+			opts, err := getWatchOptionsFromFlags(cmd)
+			if err != nil {
+				logger.Error(err.Error())
+				os.Exit(1)
+			}
+			err = runWatch(httpClient, logger, opts)
+			if err != nil {
+				logger.Error(err.Error())
+				os.Exit(exitCode(err))
+			}
+		},
+	}
+)
+
+func getWatchOptionsFromFlags(cmd *cobra.Command) (provider.WatchOptions, error) {
+	minInterval, err := cmd.Flags().GetDuration(minIntervalKey)
+	if err != nil {
+		return provider.WatchOptions{}, err
+	}
+	maxInterval, err := cmd.Flags().GetDuration(maxIntervalKey)
+	if err != nil {
+		return provider.WatchOptions{}, err
+	}
+
+	return provider.WatchOptions{
+		MinInterval: minInterval,
+		MaxInterval: maxInterval,
+	}, nil
+}
+
+func runWatch(httpClient *http.Client, logger *slog.Logger, opts provider.WatchOptions) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	providerName, err := readConfig(providerKey)
+	if err != nil {
+		return fmt.Errorf("could not read config for %#q, %w", providerKey, err)
+	}
+	locationCode, err := readConfig(locationKey)
+	if err != nil {
+		return fmt.Errorf("could not read config for %#q, %w", locationKey, err)
+	}
+	if locationCode == "" {
+		return fmt.Errorf("%#q must be set", locationKey)
+	}
+
+	// Cache filename is empty so we use in-memory cache.
+	client, err := getClient(providerName, "", httpClient, logger)
+	if err != nil {
+		return fmt.Errorf("could not get client, %w", err)
+	}
+
+	err = writeConfig()
+	if err != nil {
+		return fmt.Errorf("could not write config, %w", err)
+	}
+
+	// Merge the updates for each location so they are printed in turn.
+	updates := make(chan provider.Update)
+
+	var wg sync.WaitGroup
+	for _, location := range strings.Split(locationCode, ",") {
+		wg.Add(1)
+		go func(location string) {
+			defer wg.Done()
+			for update := range provider.Watch(ctx, client, location, opts) {
+				updates <- update
+			}
+		}(location)
+	}
+	go func() {
+		wg.Wait()
+		close(updates)
+	}()
+
+	for update := range updates {
+		if update.Err != nil {
+			logger.Error("could not get carbon intensity",
+				"location", update.Location,
+				"error", update.Err)
+			continue
+		}
+
+		bytes, err := json.MarshalIndent(update.Data, "", "\t")
+		if err != nil {
+			// Keep receiving so the watches for other locations are not
+			// blocked.
+			logger.Error("could not marshal json",
+				"location", update.Location,
+				"error", err)
+			continue
+		}
+		fmt.Println(string(bytes))
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/thegreenwebfoundation/grid-intensity-go/pkg/provider"
+)
+
+func main() {
+	// Deploy the WebAPI https://github.com/Green-Software-Foundation/carbon-aware-sdk
+	url := os.Getenv("CARBON_AWARE_SDK_API_URL")
+	if url == "" {
+		log.Fatalln("please set the env variable `CARBON_AWARE_SDK_API_URL`")
+	}
+
+	c := provider.CarbonAwareSDKConfig{
+		APIURL: url,
+	}
+	p, err := provider.NewCarbonAwareSDK(c)
+	if err != nil {
+		log.Fatalln("could not make provider", err)
+	}
+
+	res, err := p.GetCarbonIntensity(context.Background(), "eastus")
+	if err != nil {
+		log.Fatalln("could not get carbon intensity", err)
+	}
+
+	bytes, err := json.MarshalIndent(res, "", "\t")
+	if err != nil {
+		log.Fatalln("could not get carbon intensity", err)
+	}
+
+	fmt.Println(string(bytes))
+}
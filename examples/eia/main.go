@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/thegreenwebfoundation/grid-intensity-go/pkg/provider"
+)
+
+func main() {
+	// Register at https://www.eia.gov/opendata/register.php
+	apiKey := os.Getenv("EIA_API_KEY")
+	if apiKey == "" {
+		log.Fatalln("please set the env variable `EIA_API_KEY`")
+	}
+
+	c := provider.EIAConfig{
+		APIKey: apiKey,
+	}
+	e, err := provider.NewEIA(c)
+	if err != nil {
+		log.Fatalln("could not make provider", err)
+	}
+
+	res, err := e.GetCarbonIntensity(context.Background(), "CISO")
+	if err != nil {
+		log.Fatalln("could not get carbon intensity", err)
+	}
+
+	bytes, err := json.MarshalIndent(res, "", "\t")
+	if err != nil {
+		log.Fatalln("could not get carbon intensity", err)
+	}
+
+	fmt.Println(string(bytes))
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/thegreenwebfoundation/grid-intensity-go/pkg/provider"
+)
+
+func main() {
+	c := provider.EnergiDataServiceConfig{}
+	p, err := provider.NewEnergiDataService(c)
+	if err != nil {
+		log.Fatalln("could not make provider", err)
+	}
+
+	res, err := p.GetCarbonIntensity(context.Background(), "DK-DK1")
+	if err != nil {
+		log.Fatalln("could not get carbon intensity", err)
+	}
+
+	bytes, err := json.MarshalIndent(res, "", "\t")
+	if err != nil {
+		log.Fatalln("could not get carbon intensity", err)
+	}
+
+	fmt.Println(string(bytes))
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/thegreenwebfoundation/grid-intensity-go/pkg/provider"
+)
+
+func main() {
+	// Register at https://transparency.entsoe.eu/ and request an API token
+	token := os.Getenv("ENTSOE_API_TOKEN")
+	if token == "" {
+		log.Fatalln("please set the env variable `ENTSOE_API_TOKEN`")
+	}
+
+	c := provider.ENTSOEConfig{
+		Token: token,
+	}
+	e, err := provider.NewENTSOE(c)
+	if err != nil {
+		log.Fatalln("could not make provider", err)
+	}
+
+	res, err := e.GetCarbonIntensity(context.Background(), "FR")
+	if err != nil {
+		log.Fatalln("could not get carbon intensity", err)
+	}
+
+	bytes, err := json.MarshalIndent(res, "", "\t")
+	if err != nil {
+		log.Fatalln("could not get carbon intensity", err)
+	}
+
+	fmt.Println(string(bytes))
+}
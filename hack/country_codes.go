@@ -4,6 +4,8 @@ import (
 	"encoding/csv"
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -11,6 +13,15 @@ const (
 	countryCode     = "country_code"
 	countryCodeISO2 = "country_code_iso_2"
 	countryCodeISO3 = "country_code_iso_3"
+
+	// Columns of the Ember yearly electricity data in long format.
+	areaColumn        = "Area"
+	countryCodeColumn = "Country code"
+	yearColumn        = "Year"
+	variableColumn    = "Variable"
+	valueColumn       = "Value"
+
+	co2IntensityVariable = "CO2 intensity"
 )
 
 func main() {
@@ -20,9 +31,18 @@ func main() {
 	}
 }
 
+// emberYear is the CO2 intensity of a country or aggregate for a year.
+type emberYear struct {
+	countryCodeISO3 string
+	area            string
+	year            int
+	value           string
+}
+
 // mapCountryCodes takes in an Ember data file with country_code and 3 char
 // ISO codes. The data is transformed to have both 2 and 3 char ISO codes so
-// users of the CLI can use either format.
+// users of the CLI can use either format. Ember yearly electricity data in
+// long format is converted by convertLongFormat.
 func mapCountryCodes(inputFile string) error {
 	countries, err := getCountryLookups()
 	if err != nil {
@@ -39,6 +59,12 @@ func mapCountryCodes(inputFile string) error {
 		return err
 	}
 
+	for _, name := range rows[0] {
+		if name == variableColumn {
+			return convertLongFormat(countries, rows)
+		}
+	}
+
 	err = updateHeader(rows[0])
 	if err != nil {
 		return err
@@ -67,6 +93,83 @@ func mapCountryCodes(inputFile string) error {
 	return nil
 }
 
+// convertLongFormat takes in the Ember yearly electricity data in long format
+// and outputs the CO2 intensity for every year in the format that is
+// embedded, with both 2 and 3 char ISO codes and the latest year for each
+// country.
+func convertLongFormat(countries map[string]string, rows [][]string) error {
+	columns := map[string]int{}
+	for i, name := range rows[0] {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, name := range []string{areaColumn, countryCodeColumn, yearColumn, variableColumn, valueColumn} {
+		if _, ok := columns[name]; !ok {
+			return fmt.Errorf("column %#q not found", name)
+		}
+	}
+
+	var years []emberYear
+	latestYears := map[string]int{}
+
+	for _, row := range rows[1:] {
+		if row[columns[variableColumn]] != co2IntensityVariable || row[columns[valueColumn]] == "" {
+			continue
+		}
+
+		year, err := strconv.Atoi(row[columns[yearColumn]])
+		if err != nil {
+			return err
+		}
+
+		area := row[columns[areaColumn]]
+		if year > latestYears[area] {
+			latestYears[area] = year
+		}
+
+		years = append(years, emberYear{
+			countryCodeISO3: row[columns[countryCodeColumn]],
+			area:            area,
+			year:            year,
+			value:           row[columns[valueColumn]],
+		})
+	}
+
+	sort.Slice(years, func(i, j int) bool {
+		if years[i].area != years[j].area {
+			return years[i].area < years[j].area
+		}
+		return years[i].year < years[j].year
+	})
+
+	w := csv.NewWriter(os.Stdout)
+	err := w.Write([]string{countryCodeISO2, countryCodeISO3, "country_or_region", "year", "latest_year", "emissions_intensity_gco2_per_kwh"})
+	if err != nil {
+		return err
+	}
+
+	for _, y := range years {
+		iso_2, ok := countries[y.countryCodeISO3]
+		if !ok && y.countryCodeISO3 != "" {
+			return fmt.Errorf("country %#q not found", y.countryCodeISO3)
+		}
+
+		err = w.Write([]string{
+			iso_2,
+			y.countryCodeISO3,
+			y.area,
+			strconv.Itoa(y.year),
+			strconv.Itoa(latestYears[y.area]),
+			y.value,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	w.Flush()
+
+	return w.Error()
+}
+
 func getCountryLookups() (map[string]string, error) {
 	countries := map[string]string{}
 
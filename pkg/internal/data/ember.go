@@ -4,56 +4,414 @@ import (
 	"bytes"
 	_ "embed"
 	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 )
 
-//go:embed co2-intensities-ember-2021.csv
+const (
+	// Columns of the processed data with both 2 and 3 char ISO codes.
+	countryCodeISO2Column = "country_code_iso_2"
+	countryCodeISO3Column = "country_code_iso_3"
+	countryOrRegionColumn = "country_or_region"
+	yearColumn            = "year"
+	latestYearColumn      = "latest_year"
+	intensityColumn       = "emissions_intensity_gco2_per_kwh"
+
+	// Columns of the Ember yearly electricity data in long format.
+	areaColumn        = "Area"
+	countryCodeColumn = "Country code"
+	longYearColumn    = "Year"
+	dateColumn        = "Date"
+	variableColumn    = "Variable"
+	unitColumn        = "Unit"
+	valueColumn       = "Value"
+
+	co2IntensityVariable = "CO2 intensity"
+)
+
+//go:embed co2-intensities-ember.csv
 var emberData []byte
 
-func GetEmberGridIntensity() (map[string]EmberGridIntensity, error) {
-	data := map[string]EmberGridIntensity{}
+// EmberData is grid intensity data keyed by country code and then by year.
+// Both 2 and 3 char ISO country codes are present.
+type EmberData map[string]map[int]EmberGridIntensity
+
+// GetEmberGridIntensity returns the Ember data embedded in the binary.
+func GetEmberGridIntensity() (EmberData, error) {
+	return ParseEmberGridIntensity(bytes.NewReader(emberData))
+}
+
+// LoadEmberGridIntensity returns the embedded Ember data merged with the data
+// from a CSV file. Values from the file take precedence so newer Ember
+// releases can be used without rebuilding the binary.
+func LoadEmberGridIntensity(path string) (EmberData, error) {
+	result, err := GetEmberGridIntensity()
+	if err != nil {
+		return nil, err
+	}
 
-	reader := bytes.NewReader(emberData)
-	rows, err := csv.NewReader(reader).ReadAll()
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	fileData, err := ParseEmberGridIntensity(f)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", path, err)
+	}
+
+	// Data files in Ember's format only have 3 char ISO codes so we use the
+	// embedded data to also add the 2 char codes.
+	iso2Codes := map[string]string{}
+	for _, years := range result {
+		for _, country := range years {
+			if country.CountryCodeISO2 != "" {
+				iso2Codes[country.CountryCodeISO3] = country.CountryCodeISO2
+			}
+		}
+	}
+
+	for _, years := range fileData {
+		for _, country := range years {
+			if country.CountryCodeISO2 == "" {
+				country.CountryCodeISO2 = iso2Codes[country.CountryCodeISO3]
+			}
+			result.add(country)
+		}
+	}
+
+	return result, nil
+}
+
+// ParseEmberGridIntensity parses Ember data in CSV format. Both the processed
+// format that is embedded and the Ember yearly electricity data in long
+// format are supported.
+func ParseEmberGridIntensity(r io.Reader) (EmberData, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("no data found")
+	}
+
+	columns := map[string]int{}
+	for i, name := range rows[0] {
+		columns[strings.TrimSpace(name)] = i
+	}
+
+	if _, ok := columns[countryCodeISO2Column]; ok {
+		return parseProcessedData(columns, rows[1:])
+	}
+	if _, ok := columns[variableColumn]; ok {
+		return parseLongFormatData(columns, rows[1:])
+	}
+
+	return nil, fmt.Errorf("header %#q not recognized", rows[0])
+}
+
+func parseProcessedData(columns map[string]int, rows [][]string) (EmberData, error) {
+	data := EmberData{}
+
+	err := requireColumns(columns, countryCodeISO2Column, countryCodeISO3Column, countryOrRegionColumn,
+		yearColumn, latestYearColumn, intensityColumn)
 	if err != nil {
 		return nil, err
 	}
 
 	for _, row := range rows {
-		countryCodeISO2 := row[0]
-		countryCodeISO3 := row[1]
-		if countryCodeISO2 == "" || countryCodeISO2 == "country_code_iso_2" {
-			continue
+		countryCodeISO2 := row[columns[countryCodeISO2Column]]
+		countryCodeISO3 := row[columns[countryCodeISO3Column]]
+		countryOrRegion := row[columns[countryOrRegionColumn]]
+
+		// Rows without country codes are aggregates such as EU or World.
+		var aggregateCode string
+		if countryCodeISO2 == "" && countryCodeISO3 == "" {
+			aggregateCode = AggregateCode(countryOrRegion)
 		}
 
-		year, err := strconv.Atoi(row[3])
+		year, err := strconv.Atoi(row[columns[yearColumn]])
 		if err != nil {
 			return nil, err
 		}
 
-		latestYear, err := strconv.Atoi(row[4])
+		latestYear, err := strconv.Atoi(row[columns[latestYearColumn]])
 		if err != nil {
 			return nil, err
 		}
 
-		intensity, err := strconv.ParseFloat(row[5], 64)
+		intensity, err := strconv.ParseFloat(row[columns[intensityColumn]], 64)
 		if err != nil {
 			return nil, err
 		}
 
-		country := EmberGridIntensity{
+		data.add(EmberGridIntensity{
 			CountryCodeISO2:              countryCodeISO2,
 			CountryCodeISO3:              countryCodeISO3,
-			CountryOrRegion:              row[2],
+			CountryOrRegion:              countryOrRegion,
+			AggregateCode:                aggregateCode,
 			Year:                         year,
 			LatestYear:                   latestYear,
 			EmissionsIntensityGCO2PerKWH: intensity,
+		})
+	}
+
+	return data, nil
+}
+
+func parseLongFormatData(columns map[string]int, rows [][]string) (EmberData, error) {
+	data := EmberData{}
+
+	err := requireColumns(columns, areaColumn, countryCodeColumn, longYearColumn,
+		variableColumn, unitColumn, valueColumn)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		if row[columns[variableColumn]] != co2IntensityVariable {
+			continue
+		}
+		if row[columns[valueColumn]] == "" {
+			continue
+		}
+
+		year, err := strconv.Atoi(row[columns[longYearColumn]])
+		if err != nil {
+			return nil, err
+		}
+
+		intensity, err := strconv.ParseFloat(row[columns[valueColumn]], 64)
+		if err != nil {
+			return nil, err
+		}
+
+		countryCodeISO3 := row[columns[countryCodeColumn]]
+		area := row[columns[areaColumn]]
+
+		// Rows without a country code are aggregates such as EU or World.
+		var aggregateCode string
+		if countryCodeISO3 == "" {
+			aggregateCode = AggregateCode(area)
+		}
+
+		data.add(EmberGridIntensity{
+			CountryCodeISO3:              countryCodeISO3,
+			CountryOrRegion:              area,
+			AggregateCode:                aggregateCode,
+			Year:                         year,
+			EmissionsIntensityGCO2PerKWH: intensity,
+		})
+	}
+
+	// The latest year is not included in the long format so calculate it.
+	for _, years := range data {
+		latestYear := 0
+		for year := range years {
+			if year > latestYear {
+				latestYear = year
+			}
+		}
+		for year, country := range years {
+			country.LatestYear = latestYear
+			years[year] = country
+		}
+	}
+
+	return data, nil
+}
+
+// EmberMonthlyData is monthly grid intensity data keyed by country code and
+// then by the first day of the month in UTC.
+type EmberMonthlyData map[string]map[time.Time]EmberGridIntensity
+
+// LoadEmberMonthlyGridIntensity returns the monthly Ember data from a CSV file
+// in Ember's monthly electricity data long format. Monthly data is not
+// embedded in the binary.
+func LoadEmberMonthlyGridIntensity(path string) (EmberMonthlyData, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	result, err := ParseEmberMonthlyGridIntensity(f)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", path, err)
+	}
+
+	// Add the 2 char ISO codes using the embedded data.
+	yearly, err := GetEmberGridIntensity()
+	if err != nil {
+		return nil, err
+	}
+	for _, years := range yearly {
+		for _, country := range years {
+			if country.CountryCodeISO2 == "" {
+				continue
+			}
+			if months, ok := result[country.CountryCodeISO3]; ok {
+				result[country.CountryCodeISO2] = months
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// ParseEmberMonthlyGridIntensity parses Ember's monthly electricity data in
+// long format.
+func ParseEmberMonthlyGridIntensity(r io.Reader) (EmberMonthlyData, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("no data found")
+	}
+
+	columns := map[string]int{}
+	for i, name := range rows[0] {
+		columns[strings.TrimSpace(name)] = i
+	}
+
+	err = requireColumns(columns, areaColumn, countryCodeColumn, dateColumn,
+		variableColumn, unitColumn, valueColumn)
+	if err != nil {
+		return nil, err
+	}
+
+	data := EmberMonthlyData{}
+
+	for _, row := range rows[1:] {
+		if row[columns[variableColumn]] != co2IntensityVariable {
+			continue
+		}
+		if row[columns[valueColumn]] == "" {
+			continue
+		}
+
+		date, err := time.Parse("2006-01-02", row[columns[dateColumn]])
+		if err != nil {
+			return nil, err
+		}
+		month := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+		intensity, err := strconv.ParseFloat(row[columns[valueColumn]], 64)
+		if err != nil {
+			return nil, err
 		}
 
-		// Add both country codes to allow lookups with either format.
-		data[countryCodeISO2] = country
-		data[countryCodeISO3] = country
+		countryCodeISO3 := row[columns[countryCodeColumn]]
+		area := row[columns[areaColumn]]
+
+		// Rows without a country code are aggregates such as EU or World.
+		code := countryCodeISO3
+		var aggregateCode string
+		if countryCodeISO3 == "" {
+			aggregateCode = AggregateCode(area)
+			code = aggregateCode
+		}
+
+		if _, ok := data[code]; !ok {
+			data[code] = map[time.Time]EmberGridIntensity{}
+		}
+		data[code][month] = EmberGridIntensity{
+			CountryCodeISO3:              countryCodeISO3,
+			CountryOrRegion:              area,
+			AggregateCode:                aggregateCode,
+			Year:                         month.Year(),
+			Month:                        int(month.Month()),
+			EmissionsIntensityGCO2PerKWH: intensity,
+		}
 	}
 
 	return data, nil
 }
+
+// Latest returns the data for the most recent month for a country.
+func (e EmberMonthlyData) Latest(code string) (time.Time, EmberGridIntensity, bool) {
+	var latest time.Time
+	var result EmberGridIntensity
+	var found bool
+
+	for month, country := range e[code] {
+		if !found || month.After(latest) {
+			latest = month
+			result = country
+			found = true
+		}
+	}
+
+	return latest, result, found
+}
+
+// add adds the data for a country for both country code formats. Aggregates
+// are added using their aggregate code.
+func (e EmberData) add(country EmberGridIntensity) {
+	for _, code := range []string{country.CountryCodeISO2, country.CountryCodeISO3, country.AggregateCode} {
+		if code == "" {
+			continue
+		}
+		if _, ok := e[code]; !ok {
+			e[code] = map[int]EmberGridIntensity{}
+		}
+		e[code][country.Year] = country
+	}
+}
+
+// Latest returns the data for the most recent year for a country.
+func (e EmberData) Latest(code string) (EmberGridIntensity, bool) {
+	var result EmberGridIntensity
+	var found bool
+
+	for year, country := range e[code] {
+		if !found || year > result.Year {
+			result = country
+			found = true
+		}
+	}
+
+	return result, found
+}
+
+// Aggregates returns the codes of the aggregates such as EU or WORLD sorted
+// alphabetically.
+func (e EmberData) Aggregates() []string {
+	var result []string
+
+	for code, years := range e {
+		for _, country := range years {
+			if country.AggregateCode == code {
+				result = append(result, code)
+			}
+			break
+		}
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// AggregateCode returns the code for an aggregate from its name. The name is
+// upper cased and spaces are replaced with underscores e.g. North America is
+// NORTH_AMERICA.
+func AggregateCode(name string) string {
+	return strings.ReplaceAll(strings.ToUpper(strings.TrimSpace(name)), " ", "_")
+}
+
+func requireColumns(columns map[string]int, names ...string) error {
+	for _, name := range names {
+		if _, ok := columns[name]; !ok {
+			return fmt.Errorf("column %#q not found", name)
+		}
+	}
+
+	return nil
+}
@@ -4,7 +4,9 @@ type EmberGridIntensity struct {
 	CountryCodeISO2              string  `json:"country_code_iso_2"`
 	CountryCodeISO3              string  `json:"country_code_iso_3"`
 	CountryOrRegion              string  `json:"country_or_region"`
+	AggregateCode                string  `json:"aggregate_code,omitempty"`
 	Year                         int     `json:"year"`
+	Month                        int     `json:"month,omitempty"`
 	LatestYear                   int     `json:"latest_year"`
 	EmissionsIntensityGCO2PerKWH float64 `json:"emissions_intensity_gco2_per_kwh"`
 }
@@ -0,0 +1,450 @@
+package provider
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// aemoRegions are the supported NEM regions.
+	aemoRegions = map[string]bool{
+		"NSW1": true,
+		"QLD1": true,
+		"SA1":  true,
+		"TAS1": true,
+		"VIC1": true,
+	}
+
+	// aemoTimeZone is NEM time which is AEST without daylight saving.
+	aemoTimeZone = time.FixedZone("AEST", 10*60*60)
+
+	aemoLinkRegexp = regexp.MustCompile(`(?i)href="([^"]+\.(?:zip|csv))"`)
+)
+
+type AEMOClient struct {
+	*httpClient
+
+	apiURL         string
+	dispatchPath   string
+	generatorsPath string
+
+	mu                 sync.Mutex
+	generators         map[string]aemoGenerator
+	generatorsExpireAt time.Time
+}
+
+type AEMOConfig struct {
+	Client    *http.Client
+	Retry     RetryConfig
+	RateLimit RateLimitConfig
+	Logger    *slog.Logger
+	APIURL    string
+	// DispatchPath is the NEMWEB directory with the dispatch unit SCADA
+	// reports. The most recent report is used.
+	DispatchPath string
+	// GeneratorsPath is the CDEII report with emission factors for each
+	// generating unit.
+	GeneratorsPath string
+}
+
+func NewAEMO(config AEMOConfig) (Interface, error) {
+	if config.Client == nil {
+		config.Client = &http.Client{
+			Timeout: 10 * time.Second,
+		}
+	}
+	if config.APIURL == "" {
+		config.APIURL = "https://nemweb.com.au"
+	}
+	if config.DispatchPath == "" {
+		config.DispatchPath = "/Reports/Current/Dispatch_SCADA/"
+	}
+	if config.GeneratorsPath == "" {
+		config.GeneratorsPath = "/Reports/Current/CDEII/CO2EII_AVAILABLE_GENERATORS.CSV"
+	}
+
+	c := &AEMOClient{
+		httpClient:     newHTTPClient(config.Client, config.Retry, config.RateLimit, newLogger(config.Logger, AEMO)),
+		apiURL:         config.APIURL,
+		dispatchPath:   config.DispatchPath,
+		generatorsPath: config.GeneratorsPath,
+	}
+
+	return c, nil
+}
+
+// GetCarbonIntensity returns the average carbon intensity for a NEM region
+// for the most recent 5 minute dispatch interval. It is calculated from the
+// SCADA output of each generating unit and its CDEII emission factor.
+func (a *AEMOClient) GetCarbonIntensity(ctx context.Context, location string) ([]CarbonIntensity, error) {
+	return a.coalesce(ctx, AEMO, location, a.getCarbonIntensity)
+}
+
+// GetCarbonIntensityMulti returns the data for multiple NEM regions. The
+// reports include all regions so they are only downloaded once.
+func (a *AEMOClient) GetCarbonIntensityMulti(ctx context.Context, locations []string) (map[string][]CarbonIntensity, map[string]error) {
+	return callProviderMulti(ctx, a.logger, locations, a.getCarbonIntensityMulti)
+}
+
+func (a *AEMOClient) getCarbonIntensityMulti(ctx context.Context, locations []string) (map[string][]CarbonIntensity, map[string]error) {
+	results := make(map[string][]CarbonIntensity, len(locations))
+	errs := map[string]error{}
+
+	var valid []string
+	for _, location := range locations {
+		if !aemoRegions[strings.ToUpper(location)] {
+			errs[location] = newError(AEMO, location, ErrInvalidLocation)
+			continue
+		}
+		valid = append(valid, location)
+	}
+	if len(valid) == 0 {
+		return results, errs
+	}
+
+	generators, scada, settlementDate, err := a.getReports(ctx)
+	if err != nil {
+		for location, err := range batchErrors(AEMO, valid, err) {
+			errs[location] = err
+		}
+		return results, errs
+	}
+
+	for _, location := range valid {
+		result, err := aemoCarbonIntensity(strings.ToUpper(location), generators, scada, settlementDate)
+		if err != nil {
+			errs[location] = newError(AEMO, location, err)
+			continue
+		}
+		results[location] = result
+	}
+
+	return results, errs
+}
+
+func (a *AEMOClient) getCarbonIntensity(ctx context.Context, location string) ([]CarbonIntensity, error) {
+	location = strings.ToUpper(location)
+	if !aemoRegions[location] {
+		return nil, ErrInvalidLocation
+	}
+
+	generators, scada, settlementDate, err := a.getReports(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return aemoCarbonIntensity(location, generators, scada, settlementDate)
+}
+
+// getReports returns the generating units and the SCADA output for the most
+// recent dispatch interval.
+func (a *AEMOClient) getReports(ctx context.Context) (map[string]aemoGenerator, map[string]float64, time.Time, error) {
+	generators, err := a.getGenerators(ctx)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+
+	scada, settlementDate, err := a.getDispatchSCADA(ctx)
+	if err != nil {
+		return nil, nil, time.Time{}, err
+	}
+
+	return generators, scada, settlementDate, nil
+}
+
+// aemoCarbonIntensity calculates the average carbon intensity for a region
+// from the output and emission factor of its generating units.
+func aemoCarbonIntensity(location string, generators map[string]aemoGenerator, scada map[string]float64, settlementDate time.Time) ([]CarbonIntensity, error) {
+	generation := map[string]float64{}
+	factors := map[string]float64{}
+
+	for duid, output := range scada {
+		generator, ok := generators[duid]
+		if !ok || generator.regionID != location {
+			continue
+		}
+		generation[duid] = output
+		factors[duid] = generator.emissionsFactor
+	}
+
+	value, ok := averageIntensity(generation, factors, "")
+	if !ok {
+		return nil, ErrNoResponse
+	}
+
+	// The settlement date is the end of the dispatch interval.
+	validTo := settlementDate.UTC()
+	validFrom := validTo.Add(-5 * time.Minute)
+
+	return []CarbonIntensity{
+		{
+			EmissionsType: AverageEmissionsType,
+			MetricType:    AbsoluteMetricType,
+			Provider:      AEMO,
+			Location:      location,
+			Units:         GramsCO2EPerkWh,
+			ValidFrom:     validFrom,
+			ValidTo:       validTo,
+			Value:         value,
+			IsEstimated:   false,
+		},
+	}, nil
+}
+
+// getGenerators returns the CDEII generating units keyed by DUID. The report
+// is published weekly so it is cached for a day. The report is downloaded
+// without holding the lock so callers aren't blocked by a slow request.
+func (a *AEMOClient) getGenerators(ctx context.Context) (map[string]aemoGenerator, error) {
+	a.mu.Lock()
+	generators, expireAt := a.generators, a.generatorsExpireAt
+	a.mu.Unlock()
+
+	if generators != nil && time.Now().Before(expireAt) {
+		return generators, nil
+	}
+
+	generators, err := a.fetchGenerators(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	a.generators = generators
+	a.generatorsExpireAt = time.Now().Add(24 * time.Hour)
+	a.mu.Unlock()
+
+	return generators, nil
+}
+
+// fetchGenerators downloads the CDEII report and returns the generating units
+// keyed by DUID.
+func (a *AEMOClient) fetchGenerators(ctx context.Context) (map[string]aemoGenerator, error) {
+	generatorsURL, err := buildURL(a.apiURL, a.generatorsPath)
+	if err != nil {
+		return nil, err
+	}
+	data, err := a.getReport(ctx, generatorsURL)
+	if err != nil {
+		return nil, err
+	}
+
+	tables, err := parseAEMOReport(data)
+	if err != nil {
+		return nil, err
+	}
+
+	generators := map[string]aemoGenerator{}
+
+	for _, row := range tables["CO2EII_PUBLISHING"] {
+		factor, err := strconv.ParseFloat(row["CO2E_EMISSIONS_FACTOR"], 64)
+		if err != nil {
+			return nil, err
+		}
+		generators[row["DUID"]] = aemoGenerator{
+			regionID: row["REGIONID"],
+			// Convert from tCO2e per MWh.
+			emissionsFactor: factor * 1000,
+		}
+	}
+	if len(generators) == 0 {
+		return nil, ErrNoResponse
+	}
+
+	return generators, nil
+}
+
+// getDispatchSCADA returns the output in MW of each generating unit keyed by
+// DUID and the settlement date for the most recent dispatch interval.
+func (a *AEMOClient) getDispatchSCADA(ctx context.Context) (map[string]float64, time.Time, error) {
+	dispatchURL, err := buildURL(a.apiURL, a.dispatchPath)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	reportURL, err := a.getLatestReportURL(ctx, dispatchURL)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	data, err := a.getReport(ctx, reportURL)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	tables, err := parseAEMOReport(data)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	var settlementDate time.Time
+	scada := map[string]float64{}
+
+	for _, row := range tables["DISPATCH_UNIT_SCADA"] {
+		date, err := time.ParseInLocation("2006/01/02 15:04:05", row["SETTLEMENTDATE"], aemoTimeZone)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		value, err := strconv.ParseFloat(row["SCADAVALUE"], 64)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+
+		if date.After(settlementDate) {
+			settlementDate = date
+			scada = map[string]float64{}
+		}
+		if date.Equal(settlementDate) {
+			scada[row["DUID"]] = value
+		}
+	}
+	if len(scada) == 0 {
+		return nil, time.Time{}, ErrNoResponse
+	}
+
+	return scada, settlementDate, nil
+}
+
+// getLatestReportURL returns the URL of the most recent report in a NEMWEB
+// directory listing. Report filenames include a timestamp so they sort in
+// time order.
+func (a *AEMOClient) getLatestReportURL(ctx context.Context, dirURL string) (string, error) {
+	data, err := a.get(ctx, dirURL)
+	if err != nil {
+		return "", err
+	}
+
+	var links []string
+	for _, match := range aemoLinkRegexp.FindAllStringSubmatch(string(data), -1) {
+		links = append(links, match[1])
+	}
+	if len(links) == 0 {
+		return "", ErrNoResponse
+	}
+
+	sort.Slice(links, func(i, j int) bool {
+		return strings.ToUpper(links[i]) < strings.ToUpper(links[j])
+	})
+	latest := links[len(links)-1]
+
+	// Links are usually absolute paths.
+	if strings.HasPrefix(latest, "/") {
+		return buildURL(a.apiURL, latest)
+	}
+
+	return buildURL(dirURL, latest)
+}
+
+// getReport returns the CSV data for a report. Zipped reports are extracted.
+func (a *AEMOClient) getReport(ctx context.Context, reportURL string) ([]byte, error) {
+	data, err := a.get(ctx, reportURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.HasSuffix(strings.ToLower(reportURL), ".zip") {
+		return data, nil
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range archive.File {
+		if !strings.HasSuffix(strings.ToLower(file.Name), ".csv") {
+			continue
+		}
+
+		f, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+
+		return io.ReadAll(f)
+	}
+
+	return nil, fmt.Errorf("no csv file found in %s", reportURL)
+}
+
+func (a *AEMOClient) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := a.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errBadStatus(resp)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// parseAEMOReport parses a report in the AEMO MMS CSV format. Rows starting
+// with I are headers for a table and rows starting with D are data for the
+// most recent header. Comment rows starting with C are ignored. The result
+// is keyed by table name e.g. DISPATCH_UNIT_SCADA.
+func parseAEMOReport(data []byte) (map[string][]map[string]string, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	// Rows have different numbers of fields.
+	reader.FieldsPerRecord = -1
+
+	tables := map[string][]map[string]string{}
+
+	var table string
+	var header []string
+
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if len(row) < 4 {
+			continue
+		}
+
+		switch row[0] {
+		case "I":
+			table = row[1] + "_" + row[2]
+			header = row[4:]
+		case "D":
+			if header == nil {
+				return nil, fmt.Errorf("data row found before header row")
+			}
+			record := make(map[string]string, len(header))
+			for i, name := range header {
+				if i+4 < len(row) {
+					record[name] = row[i+4]
+				}
+			}
+			tables[table] = append(tables[table], record)
+		}
+	}
+
+	return tables, nil
+}
+
+type aemoGenerator struct {
+	regionID string
+	// emissionsFactor is in gCO2e per kWh.
+	emissionsFactor float64
+}
@@ -0,0 +1,192 @@
+package provider
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var MockAEMODirectoryListing = `<html><body><pre>
+<a href="/Reports/Current/">[To Parent Directory]</a><br>
+<a href="/Reports/Current/Dispatch_SCADA/PUBLIC_DISPATCHSCADA_202307010955_0000000391234561.zip">PUBLIC_DISPATCHSCADA_202307010955_0000000391234561.zip</a><br>
+<a href="/Reports/Current/Dispatch_SCADA/PUBLIC_DISPATCHSCADA_202307011000_0000000391234567.zip">PUBLIC_DISPATCHSCADA_202307011000_0000000391234567.zip</a><br>
+</pre></body></html>`
+
+func makeAEMOTestServer(t *testing.T) *httptest.Server {
+	scada, err := os.ReadFile("testdata/aemo_dispatch_scada.csv")
+	if err != nil {
+		t.Fatalf("could not read fixture: %s", err)
+	}
+	generators, err := os.ReadFile("testdata/aemo_co2eii_available_generators.csv")
+	if err != nil {
+		t.Fatalf("could not read fixture: %s", err)
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/Reports/Current/Dispatch_SCADA":
+			fmt.Fprintln(w, MockAEMODirectoryListing)
+		case "/Reports/Current/Dispatch_SCADA/PUBLIC_DISPATCHSCADA_202307011000_0000000391234567.zip":
+			archive := zip.NewWriter(w)
+			f, err := archive.Create("PUBLIC_DISPATCHSCADA_202307011000_0000000391234567.CSV")
+			if err != nil {
+				t.Fatalf("could not create zip: %s", err)
+			}
+			f.Write(scada)
+			archive.Close()
+		case "/Reports/Current/CDEII/CO2EII_AVAILABLE_GENERATORS.CSV":
+			w.Write(generators)
+		default:
+			t.Errorf("unknown path %#q", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func Test_AEMO_SimpleRequest(t *testing.T) {
+	ts := makeAEMOTestServer(t)
+	defer ts.Close()
+
+	c := AEMOConfig{
+		APIURL: ts.URL,
+	}
+	a, err := NewAEMO(c)
+	if err != nil {
+		t.Errorf("Could not make provider: %s", err)
+		return
+	}
+
+	res, err := a.GetCarbonIntensity(context.Background(), "nsw1")
+	if err != nil {
+		t.Fatalf("got error on GetCarbonIntensity: %s", err)
+	}
+
+	expected := []CarbonIntensity{
+		{
+			EmissionsType: "average",
+			MetricType:    "absolute",
+			Provider:      "AEMO",
+			Location:      "NSW1",
+			Units:         "gCO2e per kWh",
+			ValidFrom:     time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC).Add(-5 * time.Minute),
+			ValidTo:       time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC),
+			// (650*900 + 200*400 + 150*0) / 1000
+			Value:       665,
+			IsEstimated: false,
+		},
+	}
+	if !reflect.DeepEqual(expected, res) {
+		t.Errorf("want matching \n %s", cmp.Diff(res, expected))
+	}
+}
+
+func Test_AEMO_Multi(t *testing.T) {
+	var requests int32
+
+	ts := makeAEMOTestServer(t)
+	defer ts.Close()
+
+	handler := ts.Config.Handler
+	ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		handler.ServeHTTP(w, r)
+	})
+
+	c := AEMOConfig{
+		APIURL: ts.URL,
+	}
+	a, err := NewAEMO(c)
+	if err != nil {
+		t.Fatalf("Could not make provider: %s", err)
+	}
+
+	results, errs := GetCarbonIntensityMulti(context.Background(), a, []string{"NSW1", "VIC1", "QLD1", "XX"}, 0)
+
+	if len(results["NSW1"]) != 1 || results["NSW1"][0].Value != 665 {
+		t.Errorf("unexpected result for NSW1 %v", results["NSW1"])
+	}
+	if len(results["VIC1"]) != 1 || results["VIC1"][0].Value != 1200 {
+		t.Errorf("unexpected result for VIC1 %v", results["VIC1"])
+	}
+	if !errors.Is(errs["QLD1"], ErrNoResponse) {
+		t.Errorf("expected %v for QLD1 got %v", ErrNoResponse, errs["QLD1"])
+	}
+	if !errors.Is(errs["XX"], ErrInvalidLocation) {
+		t.Errorf("expected %v for XX got %v", ErrInvalidLocation, errs["XX"])
+	}
+	if len(results) != 2 || len(errs) != 2 {
+		t.Errorf("expected 2 results and 2 errors got %d and %d", len(results), len(errs))
+	}
+
+	// The directory listing, dispatch report and generators report are only
+	// downloaded once.
+	if n := atomic.LoadInt32(&requests); n != 3 {
+		t.Errorf("expected 3 requests got %d", n)
+	}
+}
+
+func Test_AEMO_ConcurrentGenerators(t *testing.T) {
+	var requests int32
+	bothStarted := make(chan struct{})
+
+	ts := makeAEMOTestServer(t)
+	defer ts.Close()
+
+	// The first generators request only completes once the second has
+	// started so it fails if requests are made while holding the lock.
+	handler := ts.Config.Handler
+	ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&requests, 1) {
+		case 1:
+			select {
+			case <-bothStarted:
+			case <-time.After(time.Second):
+				t.Errorf("second request was blocked by the first")
+			}
+		case 2:
+			close(bothStarted)
+		}
+		handler.ServeHTTP(w, r)
+	})
+
+	c := AEMOConfig{
+		APIURL: ts.URL,
+	}
+	a, err := NewAEMO(c)
+	if err != nil {
+		t.Fatalf("Could not make provider: %s", err)
+	}
+	client := a.(*AEMOClient)
+
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := client.getGenerators(context.Background())
+			errs <- err
+		}()
+	}
+	for i := 0; i < 2; i++ {
+		if err := <-errs; err != nil {
+			t.Errorf("got error on getGenerators: %s", err)
+		}
+	}
+
+	// The cached generators are used once they are downloaded.
+	_, err = client.getGenerators(context.Background())
+	if err != nil {
+		t.Fatalf("got error on getGenerators: %s", err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("expected 2 requests got %d", n)
+	}
+}
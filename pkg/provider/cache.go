@@ -6,7 +6,6 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
-	"io/ioutil"
 	"os"
 	"path/filepath"
 	"time"
@@ -15,6 +14,10 @@ import (
 	"github.com/jellydator/ttlcache/v2"
 )
 
+// tokenCacheKey is the key for the login token in the cache file. It can't
+// be a location as locations don't start with an underscore.
+const tokenCacheKey = "_token"
+
 type cacheConfig struct {
 	CacheFile string
 	LockFile  string
@@ -27,8 +30,9 @@ type cacheStore struct {
 }
 
 type cacheData struct {
-	Data []CarbonIntensity `json:"data"`
-	TTL  time.Time         `json:"ttl"`
+	Data  []CarbonIntensity `json:"data"`
+	Token string            `json:"token,omitempty"`
+	TTL   time.Time         `json:"ttl"`
 }
 
 func NewCacheStore(config cacheConfig) (*cacheStore, error) {
@@ -104,6 +108,41 @@ func (c *cacheStore) setCacheData(ctx context.Context, location string, data []C
 	return nil
 }
 
+// getCacheToken returns the login token from the cache file if it has not
+// expired.
+func (c *cacheStore) getCacheToken(ctx context.Context) (string, time.Time, error) {
+	if c.cacheFile == "" {
+		return "", time.Time{}, nil
+	}
+
+	cache, err := c.loadCache(ctx)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	item, ok := cache[tokenCacheKey]
+	if !ok || item.TTL.Before(time.Now()) {
+		return "", time.Time{}, nil
+	}
+
+	return item.Token, item.TTL, nil
+}
+
+// setCacheToken saves the login token to the cache file. As the token is a
+// credential the file is made readable only by the current user.
+func (c *cacheStore) setCacheToken(ctx context.Context, token string, ttl time.Time) error {
+	if c.cacheFile == "" {
+		return nil
+	}
+
+	item := &cacheData{
+		Token: token,
+		TTL:   ttl,
+	}
+
+	return c.saveCache(ctx, tokenCacheKey, item)
+}
+
 func (c *cacheStore) loadCache(ctx context.Context) (map[string]*cacheData, error) {
 	cache := make(map[string]*cacheData, 0)
 
@@ -166,9 +205,31 @@ func (c *cacheStore) saveCache(ctx context.Context, location string, item *cache
 		return err
 	}
 
-	err = ioutil.WriteFile(c.cacheFile, data, 0644)
+	return writeCacheFile(c.cacheFile, data)
+}
+
+// writeCacheFile writes the data to a temp file that is renamed to the cache
+// file. Temp files are only readable by the user so the cache file is never
+// readable by others as it may contain an API token.
+func writeCacheFile(cacheFile string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(cacheFile), filepath.Base(cacheFile)+".*.tmp")
 	if err != nil {
-		return nil
+		return err
+	}
+
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	err = os.Rename(f.Name(), cacheFile)
+	if err != nil {
+		os.Remove(f.Name())
+		return err
 	}
 
 	return nil
@@ -0,0 +1,248 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type CarbonAwareSDKClient struct {
+	*httpClient
+
+	apiURL          string
+	emissionsType   string
+	disableForecast bool
+}
+
+type CarbonAwareSDKConfig struct {
+	Client    *http.Client
+	Retry     RetryConfig
+	RateLimit RateLimitConfig
+	Logger    *slog.Logger
+	APIURL    string
+	// EmissionsType of the data source configured for the WebAPI. WattTime
+	// provides marginal data and Electricity Maps provides average data.
+	EmissionsType string
+	// DisableForecast only fetches emissions data for data sources that do
+	// not support forecasts.
+	DisableForecast bool
+}
+
+func NewCarbonAwareSDK(config CarbonAwareSDKConfig) (Interface, error) {
+	if config.Client == nil {
+		config.Client = &http.Client{
+			Timeout: 10 * time.Second,
+		}
+	}
+	if config.APIURL == "" {
+		config.APIURL = "http://localhost:5073"
+	}
+	if config.EmissionsType == "" {
+		config.EmissionsType = MarginalEmissionsType
+	}
+
+	c := &CarbonAwareSDKClient{
+		httpClient:      newHTTPClient(config.Client, config.Retry, config.RateLimit, newLogger(config.Logger, CarbonAwareSDK)),
+		apiURL:          config.APIURL,
+		emissionsType:   config.EmissionsType,
+		disableForecast: config.DisableForecast,
+	}
+
+	return c, nil
+}
+
+// GetCarbonIntensity returns the most recent emissions data for the location
+// followed by the current forecast. If the forecast fails the emissions data
+// is still returned. Locations are those supported by the WebAPI e.g. Azure
+// regions such as eastus.
+func (c *CarbonAwareSDKClient) GetCarbonIntensity(ctx context.Context, location string) ([]CarbonIntensity, error) {
+	return c.coalesce(ctx, CarbonAwareSDK, location, c.getCarbonIntensity)
+}
+
+func (c *CarbonAwareSDKClient) getCarbonIntensity(ctx context.Context, location string) ([]CarbonIntensity, error) {
+	if location == "" {
+		return nil, ErrInvalidLocation
+	}
+
+	now := time.Now().UTC()
+
+	emissionsURL, err := buildURL(c.apiURL, "/emissions/bylocation?"+url.Values{
+		"location": []string{location},
+		"time":     []string{now.Add(-1 * time.Hour).Format(time.RFC3339)},
+		"toTime":   []string{now.Format(time.RFC3339)},
+	}.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	emissions := []carbonAwareSDKEmissionsData{}
+	err = c.get(ctx, emissionsURL, &emissions)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []CarbonIntensity
+
+	// Use the most recent emissions data.
+	var latest *carbonAwareSDKEmissionsData
+	for i := range emissions {
+		if latest == nil || emissions[i].Time.After(latest.Time) {
+			latest = &emissions[i]
+		}
+	}
+	if latest != nil {
+		duration, err := parseTimeSpan(latest.Duration)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, c.toCarbonIntensity(location, latest.Time, duration, latest.Rating, false))
+	}
+
+	if !c.disableForecast {
+		forecast, err := c.getForecast(ctx, location)
+		if err != nil {
+			if len(result) == 0 {
+				return nil, err
+			}
+			// Data sources without forecasts return an error so the
+			// emissions data is still returned.
+			c.logger.WarnContext(ctx, "could not get forecast", "location", location, "error", err)
+		}
+		result = append(result, forecast...)
+	}
+
+	if len(result) == 0 {
+		return nil, ErrNoResponse
+	}
+
+	return result, nil
+}
+
+// getForecast returns the current forecast for the location.
+func (c *CarbonAwareSDKClient) getForecast(ctx context.Context, location string) ([]CarbonIntensity, error) {
+	forecastURL, err := buildURL(c.apiURL, "/emissions/forecasts/current?"+url.Values{
+		"location": []string{location},
+	}.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	forecasts := []carbonAwareSDKForecast{}
+	err = c.get(ctx, forecastURL, &forecasts)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []CarbonIntensity
+	for _, forecast := range forecasts {
+		for _, data := range forecast.ForecastData {
+			duration := time.Duration(data.Duration) * time.Minute
+			result = append(result, c.toCarbonIntensity(location, data.Timestamp, duration, data.Value, true))
+		}
+	}
+
+	return result, nil
+}
+
+func (c *CarbonAwareSDKClient) get(ctx context.Context, url string, respObj interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errBadStatus(resp)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(respObj)
+}
+
+func (c *CarbonAwareSDKClient) toCarbonIntensity(location string, validFrom time.Time, duration time.Duration, value float64, isEstimated bool) CarbonIntensity {
+	validFrom = validFrom.UTC()
+
+	return CarbonIntensity{
+		EmissionsType: c.emissionsType,
+		MetricType:    AbsoluteMetricType,
+		Provider:      CarbonAwareSDK,
+		Location:      location,
+		Units:         GramsCO2EPerkWh,
+		ValidFrom:     validFrom,
+		ValidTo:       validFrom.Add(duration),
+		Value:         value,
+		IsEstimated:   isEstimated,
+	}
+}
+
+// parseTimeSpan parses a .NET TimeSpan in the format [d.]hh:mm:ss.
+func parseTimeSpan(value string) (time.Duration, error) {
+	var days int
+	var err error
+
+	if i := strings.Index(value, "."); i >= 0 && i < strings.Index(value, ":") {
+		days, err = strconv.Atoi(value[:i])
+		if err != nil {
+			return 0, fmt.Errorf("could not parse duration %q: %w", value, err)
+		}
+		value = value[i+1:]
+	}
+
+	parts := strings.Split(value, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("could not parse duration %q", value)
+	}
+
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, fmt.Errorf("could not parse duration %q: %w", value, err)
+	}
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, fmt.Errorf("could not parse duration %q: %w", value, err)
+	}
+	seconds, err := strconv.ParseFloat(parts[2], 64)
+	if err != nil {
+		return 0, fmt.Errorf("could not parse duration %q: %w", value, err)
+	}
+
+	return time.Duration(days)*24*time.Hour +
+		time.Duration(hours)*time.Hour +
+		time.Duration(minutes)*time.Minute +
+		time.Duration(seconds*float64(time.Second)), nil
+}
+
+type carbonAwareSDKEmissionsData struct {
+	Location string    `json:"location"`
+	Time     time.Time `json:"time"`
+	Rating   float64   `json:"rating"`
+	Duration string    `json:"duration"`
+}
+
+type carbonAwareSDKForecast struct {
+	GeneratedAt  time.Time                    `json:"generatedAt"`
+	RequestedAt  time.Time                    `json:"requestedAt"`
+	Location     string                       `json:"location"`
+	DataStartAt  time.Time                    `json:"dataStartAt"`
+	DataEndAt    time.Time                    `json:"dataEndAt"`
+	WindowSize   int                          `json:"windowSize"`
+	ForecastData []carbonAwareSDKForecastData `json:"forecastData"`
+}
+
+type carbonAwareSDKForecastData struct {
+	Location  string    `json:"location"`
+	Timestamp time.Time `json:"timestamp"`
+	Duration  int       `json:"duration"`
+	Value     float64   `json:"value"`
+}
@@ -0,0 +1,157 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var MockCarbonAwareSDKEmissionsResponse = `[
+	{
+		"location": "eastus",
+		"time": "2022-08-01T00:00:00+00:00",
+		"rating": 386,
+		"duration": "00:05:00"
+	},
+	{
+		"location": "eastus",
+		"time": "2022-08-01T00:05:00+00:00",
+		"rating": 392.5,
+		"duration": "00:05:00"
+	}
+]`
+
+var MockCarbonAwareSDKForecastResponse = `[
+	{
+		"generatedAt": "2022-08-01T00:10:00+00:00",
+		"requestedAt": "2022-08-01T00:11:00+00:00",
+		"location": "eastus",
+		"dataStartAt": "2022-08-01T00:10:00+00:00",
+		"dataEndAt": "2022-08-01T00:20:00+00:00",
+		"windowSize": 5,
+		"optimalDataPoints": [],
+		"forecastData": [
+			{
+				"location": "eastus",
+				"timestamp": "2022-08-01T00:10:00Z",
+				"duration": 5,
+				"value": 400.1
+			},
+			{
+				"location": "eastus",
+				"timestamp": "2022-08-01T00:15:00Z",
+				"duration": 5,
+				"value": 398
+			}
+		]
+	}
+]`
+
+func Test_CarbonAwareSDK_SimpleRequest(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("location") != "eastus" {
+			t.Errorf("unexpected location %#q", r.URL.Query().Get("location"))
+		}
+
+		switch r.URL.Path {
+		case "/emissions/bylocation":
+			fmt.Fprintln(w, MockCarbonAwareSDKEmissionsResponse)
+		case "/emissions/forecasts/current":
+			fmt.Fprintln(w, MockCarbonAwareSDKForecastResponse)
+		default:
+			t.Errorf("unknown path %#q", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	c := CarbonAwareSDKConfig{
+		APIURL: ts.URL,
+	}
+	a, err := NewCarbonAwareSDK(c)
+	if err != nil {
+		t.Errorf("Could not make provider: %s", err)
+		return
+	}
+
+	res, err := a.GetCarbonIntensity(context.Background(), "eastus")
+	if err != nil {
+		t.Fatalf("got error on GetCarbonIntensity: %s", err)
+	}
+
+	expected := []CarbonIntensity{
+		{
+			EmissionsType: "marginal",
+			MetricType:    "absolute",
+			Provider:      "CarbonAwareSDK",
+			Location:      "eastus",
+			Units:         "gCO2e per kWh",
+			ValidFrom:     time.Date(2022, 8, 1, 0, 5, 0, 0, time.UTC),
+			ValidTo:       time.Date(2022, 8, 1, 0, 10, 0, 0, time.UTC),
+			Value:         392.5,
+			IsEstimated:   false,
+		},
+		{
+			EmissionsType: "marginal",
+			MetricType:    "absolute",
+			Provider:      "CarbonAwareSDK",
+			Location:      "eastus",
+			Units:         "gCO2e per kWh",
+			ValidFrom:     time.Date(2022, 8, 1, 0, 10, 0, 0, time.UTC),
+			ValidTo:       time.Date(2022, 8, 1, 0, 15, 0, 0, time.UTC),
+			Value:         400.1,
+			IsEstimated:   true,
+		},
+		{
+			EmissionsType: "marginal",
+			MetricType:    "absolute",
+			Provider:      "CarbonAwareSDK",
+			Location:      "eastus",
+			Units:         "gCO2e per kWh",
+			ValidFrom:     time.Date(2022, 8, 1, 0, 15, 0, 0, time.UTC),
+			ValidTo:       time.Date(2022, 8, 1, 0, 20, 0, 0, time.UTC),
+			Value:         398,
+			IsEstimated:   true,
+		},
+	}
+	if !reflect.DeepEqual(expected, res) {
+		t.Errorf("want matching \n %s", cmp.Diff(res, expected))
+	}
+}
+
+func Test_CarbonAwareSDK_ForecastError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/emissions/bylocation":
+			fmt.Fprintln(w, MockCarbonAwareSDKEmissionsResponse)
+		case "/emissions/forecasts/current":
+			// Data sources without forecasts return an error.
+			w.WriteHeader(http.StatusNotImplemented)
+		default:
+			t.Errorf("unknown path %#q", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	c := CarbonAwareSDKConfig{
+		APIURL: ts.URL,
+	}
+	a, err := NewCarbonAwareSDK(c)
+	if err != nil {
+		t.Fatalf("Could not make provider: %s", err)
+	}
+
+	// The emissions data is returned without the forecast.
+	res, err := a.GetCarbonIntensity(context.Background(), "eastus")
+	if err != nil {
+		t.Fatalf("got error on GetCarbonIntensity: %s", err)
+	}
+	if len(res) != 1 || res[0].Value != 392.5 || res[0].IsEstimated {
+		t.Errorf("expected emissions data only got %#v", res)
+	}
+}
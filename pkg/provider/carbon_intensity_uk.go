@@ -3,19 +3,23 @@ package provider
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
 	"time"
 )
 
 type CarbonIntensityUKClient struct {
-	client *http.Client
+	*httpClient
+
 	apiURL string
 }
 
 type CarbonIntensityUKConfig struct {
-	Client *http.Client
-	APIURL string
+	Client    *http.Client
+	Retry     RetryConfig
+	RateLimit RateLimitConfig
+	Logger    *slog.Logger
+	APIURL    string
 }
 
 func NewCarbonIntensityUK(config CarbonIntensityUKConfig) (Interface, error) {
@@ -29,14 +33,18 @@ func NewCarbonIntensityUK(config CarbonIntensityUKConfig) (Interface, error) {
 	}
 
 	c := &CarbonIntensityUKClient{
-		client: config.Client,
-		apiURL: config.APIURL,
+		httpClient: newHTTPClient(config.Client, config.Retry, config.RateLimit, newLogger(config.Logger, CarbonIntensityOrgUK)),
+		apiURL:     config.APIURL,
 	}
 
 	return c, nil
 }
 
 func (a *CarbonIntensityUKClient) GetCarbonIntensity(ctx context.Context, location string) ([]CarbonIntensity, error) {
+	return a.coalesce(ctx, CarbonIntensityOrgUK, location, a.getCarbonIntensity)
+}
+
+func (a *CarbonIntensityUKClient) getCarbonIntensity(ctx context.Context, location string) ([]CarbonIntensity, error) {
 	if location != "UK" {
 		return nil, ErrInvalidLocation
 	}
@@ -46,9 +54,7 @@ func (a *CarbonIntensityUKClient) GetCarbonIntensity(ctx context.Context, locati
 		return nil, err
 	}
 
-	log.Printf("calling %s", req.URL)
-
-	resp, err := a.client.Do(req)
+	resp, err := a.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,84 @@
+package provider
+
+import (
+	"context"
+	"sync"
+)
+
+// coalescer shares a single call between concurrent callers requesting the
+// same key. The zero value is ready to use.
+type coalescer struct {
+	mu    sync.Mutex
+	calls map[string]*coalescedCall
+}
+
+type coalescedCall struct {
+	done    chan struct{}
+	result  []CarbonIntensity
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// do calls fn unless a call for the key is in progress in which case its
+// result is shared. The call is cancelled once all callers have cancelled.
+// Each caller gets its own copy of the result.
+func (c *coalescer) do(ctx context.Context, key string, fn func(context.Context) ([]CarbonIntensity, error)) ([]CarbonIntensity, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if c.calls == nil {
+		c.calls = map[string]*coalescedCall{}
+	}
+
+	call, ok := c.calls[key]
+	if !ok {
+		// The call isn't cancelled with the context of the caller that
+		// started it as other callers may be waiting for it.
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+
+		call = &coalescedCall{
+			done:   make(chan struct{}),
+			cancel: cancel,
+		}
+		c.calls[key] = call
+
+		go func() {
+			defer cancel()
+
+			call.result, call.err = fn(callCtx)
+
+			c.mu.Lock()
+			if c.calls[key] == call {
+				delete(c.calls, key)
+			}
+			c.mu.Unlock()
+
+			close(call.done)
+		}()
+	}
+	call.waiters++
+	c.mu.Unlock()
+
+	select {
+	case <-call.done:
+		if call.err != nil {
+			return nil, call.err
+		}
+		return append([]CarbonIntensity(nil), call.result...), nil
+	case <-ctx.Done():
+		c.mu.Lock()
+		call.waiters--
+		if call.waiters == 0 {
+			call.cancel()
+			if c.calls[key] == call {
+				delete(c.calls, key)
+			}
+		}
+		c.mu.Unlock()
+
+		return nil, ctx.Err()
+	}
+}
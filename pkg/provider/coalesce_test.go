@@ -0,0 +1,130 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_Coalesce_ConcurrentRequests(t *testing.T) {
+	var requests int32
+	release := make(chan struct{})
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		<-release
+		fmt.Fprintln(w, MockCarbonIntensityOrgUKResponse)
+	}))
+	defer ts.Close()
+
+	c := CarbonIntensityUKConfig{
+		APIURL: ts.URL,
+	}
+	a, err := NewCarbonIntensityUK(c)
+	if err != nil {
+		t.Fatalf("Could not make provider: %s", err)
+	}
+
+	results := make([][]CarbonIntensity, 10)
+
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			res, err := a.GetCarbonIntensity(context.Background(), "UK")
+			if err != nil {
+				t.Errorf("got error on GetCarbonIntensity: %s", err)
+			}
+			results[i] = res
+		}(i)
+	}
+
+	// Wait for the request to arrive before releasing it so the callers
+	// overlap.
+	for atomic.LoadInt32(&requests) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("expected 1 request got %d", n)
+	}
+
+	// Each caller gets its own copy of the result.
+	results[0][0].Value = 0
+	for i, res := range results[1:] {
+		if len(res) != 1 || res[0].Value != 190 {
+			t.Errorf("unexpected result %d %v", i+1, res)
+		}
+	}
+}
+
+func Test_Coalesce_Cancel(t *testing.T) {
+	var c coalescer
+
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+
+	fn := func(ctx context.Context) ([]CarbonIntensity, error) {
+		close(started)
+		<-ctx.Done()
+		close(cancelled)
+		return nil, ctx.Err()
+	}
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	ctx2, cancel2 := context.WithCancel(context.Background())
+
+	errs := make(chan error, 2)
+	go func() {
+		_, err := c.do(ctx1, "UK", fn)
+		errs <- err
+	}()
+	<-started
+	go func() {
+		_, err := c.do(ctx2, "UK", fn)
+		errs <- err
+	}()
+
+	// Wait for the second caller to join the call.
+	for {
+		c.mu.Lock()
+		waiters := c.calls["UK"].waiters
+		c.mu.Unlock()
+		if waiters == 2 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// The call continues while a caller is waiting.
+	cancel1()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v got %v", context.Canceled, err)
+	}
+	select {
+	case <-cancelled:
+		t.Fatal("call cancelled while a caller is waiting")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// The call is cancelled once all callers have cancelled.
+	cancel2()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v got %v", context.Canceled, err)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("call not cancelled")
+	}
+}
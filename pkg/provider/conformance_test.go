@@ -0,0 +1,71 @@
+package provider_test
+
+import (
+	"testing"
+
+	"github.com/thegreenwebfoundation/grid-intensity-go/pkg/provider"
+	"github.com/thegreenwebfoundation/grid-intensity-go/pkg/provider/providertest"
+)
+
+func Test_Conformance_CarbonIntensityUK(t *testing.T) {
+	providertest.RunConformance(t, func(t *testing.T) providertest.Target {
+		s := providertest.NewCarbonIntensityUKServer(t)
+		c, err := provider.NewCarbonIntensityUK(s.Config())
+		if err != nil {
+			t.Fatalf("Could not make provider: %s", err)
+		}
+
+		return providertest.Target{
+			Provider:        c,
+			Location:        "UK",
+			InvalidLocation: "DE",
+			Network:         true,
+		}
+	})
+}
+
+func Test_Conformance_ElectricityMaps(t *testing.T) {
+	providertest.RunConformance(t, func(t *testing.T) providertest.Target {
+		s := providertest.NewElectricityMapsServer(t)
+		c, err := provider.NewElectricityMaps(s.Config())
+		if err != nil {
+			t.Fatalf("Could not make provider: %s", err)
+		}
+
+		return providertest.Target{
+			Provider: c,
+			Location: "IN-KA",
+			Network:  true,
+		}
+	})
+}
+
+func Test_Conformance_Ember(t *testing.T) {
+	providertest.RunConformance(t, func(t *testing.T) providertest.Target {
+		c, err := provider.NewEmber()
+		if err != nil {
+			t.Fatalf("Could not make provider: %s", err)
+		}
+
+		return providertest.Target{
+			Provider: c,
+			Location: "GBR",
+		}
+	})
+}
+
+func Test_Conformance_WattTime(t *testing.T) {
+	providertest.RunConformance(t, func(t *testing.T) providertest.Target {
+		s := providertest.NewWattTimeServer(t)
+		c, err := provider.NewWattTime(s.Config())
+		if err != nil {
+			t.Fatalf("Could not make provider: %s", err)
+		}
+
+		return providertest.Target{
+			Provider: c,
+			Location: "CAISO_NORTH",
+			Network:  true,
+		}
+	})
+}
@@ -0,0 +1,301 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultEIAEmissionFactors are lifecycle emission factors in gCO2e per kWh
+// for each EIA fuel type. They are based on the IPCC 2014 median values.
+var DefaultEIAEmissionFactors = map[string]float64{
+	"COL": 820, // Coal
+	"GEO": 38,  // Geothermal
+	"NG":  490, // Natural gas
+	"NUC": 12,  // Nuclear
+	"OIL": 650, // Petroleum
+	"OTH": 700, // Other
+	"PS":  24,  // Pumped storage
+	"SUN": 45,  // Solar
+	"WAT": 24,  // Hydro
+	"WND": 11,  // Wind
+}
+
+// eiaBatchSize is the maximum number of respondents requested at once.
+const eiaBatchSize = 10
+
+type EIAClient struct {
+	*httpClient
+
+	apiURL          string
+	apiKey          string
+	emissionFactors map[string]float64
+}
+
+type EIAConfig struct {
+	Client    *http.Client
+	Retry     RetryConfig
+	RateLimit RateLimitConfig
+	Logger    *slog.Logger
+	APIURL    string
+	APIKey    string
+	// EmissionFactors overrides the default emission factors in gCO2e per
+	// kWh keyed by EIA fuel type e.g. NG for Natural gas.
+	EmissionFactors map[string]float64
+}
+
+func NewEIA(config EIAConfig) (Interface, error) {
+	if config.Client == nil {
+		config.Client = &http.Client{
+			Timeout: 10 * time.Second,
+		}
+	}
+	if config.APIURL == "" {
+		config.APIURL = "https://api.eia.gov/v2"
+	}
+
+	c := &EIAClient{
+		httpClient:      newHTTPClient(config.Client, config.Retry, config.RateLimit, newLogger(config.Logger, EIA)),
+		apiURL:          config.APIURL,
+		apiKey:          config.APIKey,
+		emissionFactors: mergeEmissionFactors(DefaultEIAEmissionFactors, config.EmissionFactors),
+	}
+
+	return c, nil
+}
+
+// GetCarbonIntensity returns the average carbon intensity for an EIA
+// respondent code such as CISO or PJM.
+func (e *EIAClient) GetCarbonIntensity(ctx context.Context, location string) ([]CarbonIntensity, error) {
+	return e.coalesce(ctx, EIA, location, e.getCarbonIntensity)
+}
+
+// GetCarbonIntensityMulti returns the data for multiple EIA respondent codes.
+// Up to 10 respondents are requested at once.
+func (e *EIAClient) GetCarbonIntensityMulti(ctx context.Context, locations []string) (map[string][]CarbonIntensity, map[string]error) {
+	return callProviderMulti(ctx, e.logger, locations, e.getCarbonIntensityMulti)
+}
+
+func (e *EIAClient) getCarbonIntensityMulti(ctx context.Context, locations []string) (map[string][]CarbonIntensity, map[string]error) {
+	results := make(map[string][]CarbonIntensity, len(locations))
+	errs := map[string]error{}
+
+	var valid []string
+	for _, location := range locations {
+		if location == "" {
+			errs[location] = newError(EIA, location, ErrInvalidLocation)
+			continue
+		}
+		valid = append(valid, location)
+	}
+
+	for len(valid) > 0 {
+		batch := valid
+		if len(batch) > eiaBatchSize {
+			batch = batch[:eiaBatchSize]
+		}
+		valid = valid[len(batch):]
+
+		respondents := make([]string, len(batch))
+		for i, location := range batch {
+			respondents[i] = strings.ToUpper(location)
+		}
+
+		data, err := e.getFuelTypeData(ctx, respondents)
+		if err != nil {
+			for location, err := range batchErrors(EIA, batch, err) {
+				errs[location] = err
+			}
+			continue
+		}
+
+		for i, location := range batch {
+			result, err := eiaCarbonIntensity(respondents[i], data, e.emissionFactors)
+			if err != nil {
+				errs[location] = newError(EIA, location, err)
+				continue
+			}
+			results[location] = result
+		}
+	}
+
+	return results, errs
+}
+
+func (e *EIAClient) getCarbonIntensity(ctx context.Context, location string) ([]CarbonIntensity, error) {
+	location = strings.ToUpper(location)
+	if location == "" {
+		return nil, ErrInvalidLocation
+	}
+
+	data, err := e.getFuelTypeData(ctx, []string{location})
+	if err != nil {
+		return nil, err
+	}
+
+	return eiaCarbonIntensity(location, data, e.emissionFactors)
+}
+
+// getFuelTypeData returns the hourly generation per fuel type for the last
+// day for the respondents.
+func (e *EIAClient) getFuelTypeData(ctx context.Context, respondents []string) ([]eiaFuelTypes, error) {
+	start := time.Now().UTC().Add(-24 * time.Hour)
+
+	fuelTypeURL, err := e.fuelTypeDataURL(respondents, start)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fuelTypeURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	query := req.URL.Query()
+	query.Set("api_key", e.apiKey)
+	req.URL.RawQuery = query.Encode()
+
+	resp, err := e.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errBadStatus(resp)
+	}
+
+	respObj := &eiaResponse{}
+	err = json.NewDecoder(resp.Body).Decode(respObj)
+	if err != nil {
+		return nil, err
+	}
+
+	return respObj.Response.Data, nil
+}
+
+// eiaCarbonIntensity calculates the average carbon intensity for a
+// respondent for the most recent period in the data.
+func eiaCarbonIntensity(location string, data []eiaFuelTypes, emissionFactors map[string]float64) ([]CarbonIntensity, error) {
+	// Find the generation per fuel type for each period.
+	generation := map[string]map[string]float64{}
+
+	for _, row := range data {
+		if row.Respondent != location || row.Value == nil {
+			continue
+		}
+		if _, ok := generation[row.Period]; !ok {
+			generation[row.Period] = map[string]float64{}
+		}
+		generation[row.Period][row.FuelType] += float64(*row.Value)
+	}
+	if len(generation) == 0 {
+		return nil, ErrNoResponse
+	}
+
+	// Periods are formatted as YYYY-MM-DDTHH so they sort as strings.
+	periods := make([]string, 0, len(generation))
+	for period := range generation {
+		periods = append(periods, period)
+	}
+	sort.Strings(periods)
+
+	fuels := make([]map[string]float64, len(periods))
+	for i, period := range periods {
+		fuels[i] = generation[period]
+	}
+
+	// Use the most recent period where all the fuels have been reported.
+	i := latestCompletePeriod(fuels)
+	latestPeriod := periods[i]
+
+	value, ok := averageIntensity(fuels[i], emissionFactors, "OTH")
+	if !ok {
+		return nil, ErrNoResponse
+	}
+
+	// Hourly EIA-930 data is reported for the hour ending at the period.
+	validTo, err := time.Parse("2006-01-02T15", latestPeriod)
+	if err != nil {
+		return nil, err
+	}
+	validFrom := validTo.Add(-1 * time.Hour)
+
+	return []CarbonIntensity{
+		{
+			EmissionsType: AverageEmissionsType,
+			MetricType:    AbsoluteMetricType,
+			Provider:      EIA,
+			Location:      location,
+			Units:         GramsCO2EPerkWh,
+			ValidFrom:     validFrom,
+			ValidTo:       validTo,
+			Value:         value,
+			IsEstimated:   false,
+		},
+	}, nil
+}
+
+func (e *EIAClient) fuelTypeDataURL(respondents []string, start time.Time) (string, error) {
+	// Allow for a day of hourly data for each fuel type for each respondent.
+	length := 500 * len(respondents)
+	if length > 5000 {
+		length = 5000
+	}
+
+	params := url.Values{}
+	params.Set("frequency", "hourly")
+	params.Set("data[0]", "value")
+	params["facets[respondent][]"] = respondents
+	params.Set("start", start.Format("2006-01-02T15"))
+	params.Set("sort[0][column]", "period")
+	params.Set("sort[0][direction]", "desc")
+	params.Set("length", strconv.Itoa(length))
+
+	return buildURL(e.apiURL, "/electricity/rto/fuel-type-data/data/?"+params.Encode())
+}
+
+type eiaResponse struct {
+	Response eiaResponseData `json:"response"`
+}
+
+type eiaResponseData struct {
+	Total     json.Number    `json:"total"`
+	Frequency string         `json:"frequency"`
+	Data      []eiaFuelTypes `json:"data"`
+}
+
+type eiaFuelTypes struct {
+	Period     string    `json:"period"`
+	Respondent string    `json:"respondent"`
+	FuelType   string    `json:"fueltype"`
+	TypeName   string    `json:"type-name"`
+	Value      *eiaValue `json:"value"`
+	ValueUnits string    `json:"value-units"`
+}
+
+// eiaValue supports values encoded as either JSON numbers or strings since
+// the EIA API returns both.
+type eiaValue float64
+
+func (v *eiaValue) UnmarshalJSON(data []byte) error {
+	raw := strings.Trim(string(data), `"`)
+	if raw == "" || raw == "null" {
+		return nil
+	}
+
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return fmt.Errorf("could not parse value %s: %w", data, err)
+	}
+	*v = eiaValue(value)
+
+	return nil
+}
@@ -0,0 +1,203 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var MockEIAFuelTypeDataResponse = `{
+	"response": {
+		"total": "5",
+		"dateFormat": "YYYY-MM-DD\"T\"HH24",
+		"frequency": "hourly",
+		"data": [
+			{
+				"period": "2023-07-01T05",
+				"respondent": "CISO",
+				"respondent-name": "California Independent System Operator",
+				"fueltype": "NG",
+				"type-name": "Natural gas",
+				"value": "8000",
+				"value-units": "megawatthours"
+			},
+			{
+				"period": "2023-07-01T05",
+				"respondent": "CISO",
+				"respondent-name": "California Independent System Operator",
+				"fueltype": "SUN",
+				"type-name": "Solar",
+				"value": 10000,
+				"value-units": "megawatthours"
+			},
+			{
+				"period": "2023-07-01T05",
+				"respondent": "CISO",
+				"respondent-name": "California Independent System Operator",
+				"fueltype": "BAT",
+				"type-name": "Battery storage",
+				"value": -500,
+				"value-units": "megawatthours"
+			},
+			{
+				"period": "2023-07-01T05",
+				"respondent": "CISO",
+				"respondent-name": "California Independent System Operator",
+				"fueltype": "UNK",
+				"type-name": "Unknown",
+				"value": null,
+				"value-units": "megawatthours"
+			},
+			{
+				"period": "2023-07-01T04",
+				"respondent": "CISO",
+				"respondent-name": "California Independent System Operator",
+				"fueltype": "NG",
+				"type-name": "Natural gas",
+				"value": 9000,
+				"value-units": "megawatthours"
+			}
+		]
+	}
+}`
+
+func Test_EIA_SimpleRequest(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/electricity/rto/fuel-type-data/data" {
+			t.Errorf("unknown path %#q", r.URL.Path)
+		}
+		if r.URL.Query().Get("api_key") != "key" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprintln(w, MockEIAFuelTypeDataResponse)
+	}))
+	defer ts.Close()
+
+	c := EIAConfig{
+		APIURL: ts.URL,
+		APIKey: "key",
+	}
+	e, err := NewEIA(c)
+	if err != nil {
+		t.Errorf("Could not make provider: %s", err)
+		return
+	}
+
+	res, err := e.GetCarbonIntensity(context.Background(), "ciso")
+	if err != nil {
+		t.Fatalf("got error on GetCarbonIntensity: %s", err)
+	}
+
+	expected := []CarbonIntensity{
+		{
+			EmissionsType: "average",
+			MetricType:    "absolute",
+			Provider:      "EIA",
+			Location:      "CISO",
+			Units:         "gCO2e per kWh",
+			ValidFrom:     time.Date(2023, 7, 1, 4, 0, 0, 0, time.UTC),
+			ValidTo:       time.Date(2023, 7, 1, 5, 0, 0, 0, time.UTC),
+			// (8000*490 + 10000*45) / 18000
+			Value:       (8000*490 + 10000*45) / 18000.0,
+			IsEstimated: false,
+		},
+	}
+	if !reflect.DeepEqual(expected, res) {
+		t.Errorf("want matching \n %s", cmp.Diff(res, expected))
+	}
+}
+
+func Test_EIA_Multi(t *testing.T) {
+	var requests int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		respondents := r.URL.Query()["facets[respondent][]"]
+		if !reflect.DeepEqual(respondents, []string{"CISO", "PJM", "MISSING"}) {
+			t.Errorf("unexpected respondents %v", respondents)
+		}
+		fmt.Fprintln(w, `{
+	"response": {
+		"frequency": "hourly",
+		"data": [
+			{"period": "2023-07-01T05", "respondent": "CISO", "fueltype": "SUN", "value": 10000},
+			{"period": "2023-07-01T05", "respondent": "PJM", "fueltype": "NUC", "value": 5000},
+			{"period": "2023-07-01T05", "respondent": "PJM", "fueltype": "COL", "value": 5000}
+		]
+	}
+}`)
+	}))
+	defer ts.Close()
+
+	c := EIAConfig{
+		APIURL: ts.URL,
+		APIKey: "key",
+	}
+	e, err := NewEIA(c)
+	if err != nil {
+		t.Fatalf("Could not make provider: %s", err)
+	}
+
+	results, errs := GetCarbonIntensityMulti(context.Background(), e, []string{"ciso", "PJM", "missing", "PJM"}, 0)
+
+	if len(results["ciso"]) != 1 || results["ciso"][0].Value != 45 || results["ciso"][0].Location != "CISO" {
+		t.Errorf("unexpected result for ciso %v", results["ciso"])
+	}
+	if len(results["PJM"]) != 1 || results["PJM"][0].Value != (5000*12+5000*820)/10000.0 {
+		t.Errorf("unexpected result for PJM %v", results["PJM"])
+	}
+	if !errors.Is(errs["missing"], ErrNoResponse) {
+		t.Errorf("expected %v for missing got %v", ErrNoResponse, errs["missing"])
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("expected 1 request got %d", n)
+	}
+}
+
+func Test_EIA_PartialPeriod(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{
+	"response": {
+		"frequency": "hourly",
+		"data": [
+			{"period": "2023-07-01T06", "respondent": "CISO", "fueltype": "NG", "value": 8000},
+			{"period": "2023-07-01T05", "respondent": "CISO", "fueltype": "NG", "value": 8000},
+			{"period": "2023-07-01T05", "respondent": "CISO", "fueltype": "SUN", "value": 10000},
+			{"period": "2023-07-01T04", "respondent": "CISO", "fueltype": "NG", "value": 9000},
+			{"period": "2023-07-01T04", "respondent": "CISO", "fueltype": "SUN", "value": 9000}
+		]
+	}
+}`)
+	}))
+	defer ts.Close()
+
+	c := EIAConfig{
+		APIURL: ts.URL,
+		APIKey: "key",
+	}
+	e, err := NewEIA(c)
+	if err != nil {
+		t.Fatalf("Could not make provider: %s", err)
+	}
+
+	res, err := e.GetCarbonIntensity(context.Background(), "CISO")
+	if err != nil {
+		t.Fatalf("got error on GetCarbonIntensity: %s", err)
+	}
+
+	// Solar has not been reported for the final hour so the hour before it
+	// is used.
+	if len(res) != 1 || !res[0].ValidTo.Equal(time.Date(2023, 7, 1, 5, 0, 0, 0, time.UTC)) || res[0].Value != (8000*490+10000*45)/18000.0 {
+		t.Errorf("expected value for complete period got %#v", res)
+	}
+}
@@ -4,21 +4,25 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"time"
 )
 
 type ElectricityMapsClient struct {
-	client *http.Client
+	*httpClient
+
 	apiURL string
 	token  string
 }
 
 type ElectricityMapsConfig struct {
-	Client *http.Client
-	APIURL string
-	Token  string
+	Client    *http.Client
+	Retry     RetryConfig
+	RateLimit RateLimitConfig
+	Logger    *slog.Logger
+	APIURL    string
+	Token     string
 }
 
 func NewElectricityMaps(config ElectricityMapsConfig) (Interface, error) {
@@ -32,15 +36,19 @@ func NewElectricityMaps(config ElectricityMapsConfig) (Interface, error) {
 	}
 
 	c := &ElectricityMapsClient{
-		apiURL: config.APIURL,
-		client: config.Client,
-		token:  config.Token,
+		apiURL:     config.APIURL,
+		httpClient: newHTTPClient(config.Client, config.Retry, config.RateLimit, newLogger(config.Logger, ElectricityMaps)),
+		token:      config.Token,
 	}
 
 	return c, nil
 }
 
 func (e *ElectricityMapsClient) GetCarbonIntensity(ctx context.Context, location string) ([]CarbonIntensity, error) {
+	return e.coalesce(ctx, ElectricityMaps, location, e.getCarbonIntensity)
+}
+
+func (e *ElectricityMapsClient) getCarbonIntensity(ctx context.Context, location string) ([]CarbonIntensity, error) {
 	intensityURL, err := e.historicIntensityURLWithZone(location)
 	if err != nil {
 		return nil, err
@@ -52,9 +60,7 @@ func (e *ElectricityMapsClient) GetCarbonIntensity(ctx context.Context, location
 		return nil, err
 	}
 
-	log.Printf("calling %s", req.URL)
-
-	resp, err := e.client.Do(req)
+	resp, err := e.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -98,6 +104,68 @@ func (e *ElectricityMapsClient) GetCarbonIntensity(ctx context.Context, location
 	return carbonIntensityPoints, nil
 }
 
+// GetCarbonIntensityQuery returns the data for the query. If the query has a
+// time the past endpoint is used to get the value for the hour containing it.
+func (e *ElectricityMapsClient) GetCarbonIntensityQuery(ctx context.Context, query Query) ([]CarbonIntensity, error) {
+	err := query.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []CarbonIntensity
+	if query.At.IsZero() {
+		result, err = e.GetCarbonIntensity(ctx, query.Location)
+	} else {
+		result, err = callProvider(ctx, e.logger, ElectricityMaps, query.Location, func(ctx context.Context, location string) ([]CarbonIntensity, error) {
+			return e.getCarbonIntensityAt(ctx, location, query.At)
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return filterQuery(ElectricityMaps, query, result, false)
+}
+
+func (e *ElectricityMapsClient) getCarbonIntensityAt(ctx context.Context, location string, at time.Time) ([]CarbonIntensity, error) {
+	intensityURL, err := e.pastIntensityURLWithZone(location, at)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, intensityURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("auth-token", e.token)
+
+	resp, err := e.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errBadStatus(resp)
+	}
+
+	dataPoint := electricityMapsData{}
+	err = json.NewDecoder(resp.Body).Decode(&dataPoint)
+	if err != nil {
+		return nil, err
+	}
+	if dataPoint.DateTime == "" {
+		return nil, ErrNoResponse
+	}
+
+	carbonIntensity, err := toCarbonIntensity(location, dataPoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return []CarbonIntensity{*carbonIntensity}, nil
+}
+
 // Helper struct to remove clutter in the calling function
 // while finding the latest (and greatest) data points
 type electricityMapsDatapoints struct {
@@ -171,7 +239,6 @@ func stringToTime(dateTimeString string) (time.Time, error) {
 func toCarbonIntensity(location string, dataPoint electricityMapsData) (*CarbonIntensity, error) {
 	validFrom, err := time.Parse(time.RFC3339Nano, dataPoint.DateTime)
 	if err != nil {
-		log.Printf("Error parsing datetime %s", dataPoint.DateTime)
 		return nil, err
 	}
 	validTo := validFrom.Add(60 * time.Minute)
@@ -194,6 +261,11 @@ func (e *ElectricityMapsClient) historicIntensityURLWithZone(zone string) (strin
 	return buildURL(e.apiURL, zoneURL)
 }
 
+func (e *ElectricityMapsClient) pastIntensityURLWithZone(zone string, at time.Time) (string, error) {
+	zoneURL := fmt.Sprintf("/carbon-intensity/past?zone=%s&datetime=%s", zone, at.UTC().Format(time.RFC3339))
+	return buildURL(e.apiURL, zoneURL)
+}
+
 type electricityMapsData struct {
 	Zone            string  `json:"zone"`
 	CarbonIntensity float64 `json:"carbonIntensity"`
@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -63,3 +64,65 @@ func Test_ElectricityMaps_SimpleRequest(t *testing.T) {
 		t.Errorf("want matching \n %s", cmp.Diff(res, expected))
 	}
 }
+
+var MockElectricityMapsPastResponse = `{
+	"zone": "DE",
+	"carbonIntensity": 302,
+	"datetime": "2024-01-01T12:00:00.000Z",
+	"updatedAt": "2024-01-02T00:00:00.000Z",
+	"isEstimated": false
+}`
+
+func Test_ElectricityMaps_Query(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/carbon-intensity/past" {
+			t.Errorf("expected past endpoint got %s", r.URL.Path)
+		}
+		if datetime := r.URL.Query().Get("datetime"); datetime != "2024-01-01T12:30:00Z" {
+			t.Errorf("expected datetime 2024-01-01T12:30:00Z got %s", datetime)
+		}
+		fmt.Fprintln(w, MockElectricityMapsPastResponse)
+	}))
+	defer ts.Close()
+
+	c := ElectricityMapsConfig{
+		APIURL: ts.URL,
+		Token:  "token",
+	}
+	a, err := NewElectricityMaps(c)
+	if err != nil {
+		t.Fatalf("Could not make provider: %s", err)
+	}
+
+	query := Query{
+		Location: "DE",
+		At:       time.Date(2024, 1, 1, 12, 30, 0, 0, time.UTC),
+	}
+	res, err := GetCarbonIntensityQuery(context.Background(), a, query)
+	if err != nil {
+		t.Fatalf("got error on GetCarbonIntensityQuery: %s", err)
+	}
+
+	expected := []CarbonIntensity{
+		{
+			EmissionsType: "average",
+			MetricType:    "absolute",
+			Provider:      "ElectricityMaps",
+			Location:      "DE",
+			Units:         "gCO2e per kWh",
+			ValidFrom:     time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+			ValidTo:       time.Date(2024, 1, 1, 13, 0, 0, 0, time.UTC),
+			Value:         302,
+			IsEstimated:   false,
+		},
+	}
+	if !reflect.DeepEqual(expected, res) {
+		t.Errorf("want matching \n %s", cmp.Diff(res, expected))
+	}
+
+	query.EmissionsType = MarginalEmissionsType
+	_, err = GetCarbonIntensityQuery(context.Background(), a, query)
+	if !errors.Is(err, ErrNoMarginalIntensityPresent) {
+		t.Errorf("expected %v got %v", ErrNoMarginalIntensityPresent, err)
+	}
+}
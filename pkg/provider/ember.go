@@ -3,54 +3,213 @@ package provider
 import (
 	"context"
 	"fmt"
-	"strings"
+	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/thegreenwebfoundation/grid-intensity-go/pkg/internal/data"
 )
 
 const (
-	emberDataYear = 2021
+	// EmberCacheFileName is the name of the Ember data file that is loaded
+	// from the cache directory if present.
+	EmberCacheFileName = "ember-climate.org.csv"
+	// EmberMonthlyCacheFileName is the name of the Ember monthly data file
+	// that is loaded from the cache directory if present.
+	EmberMonthlyCacheFileName = "ember-climate.org-monthly.csv"
 )
 
 type EmberClient struct {
-	data map[string]data.EmberGridIntensity
+	data    data.EmberData
+	monthly data.EmberMonthlyData
+	year    int
 }
 
+type EmberConfig struct {
+	// Year of the data to return. Defaults to the latest year available for
+	// each location.
+	Year int
+	// DataFile is the path to an Ember CSV file. Its data is used in
+	// addition to the data embedded in the binary.
+	DataFile string
+	// MonthlyDataFile is the path to an Ember monthly electricity data CSV
+	// file. When set the monthly data is used in preference to yearly data.
+	MonthlyDataFile string
+	// CacheDir is checked for Ember CSV files named EmberCacheFileName and
+	// EmberMonthlyCacheFileName if DataFile or MonthlyDataFile are not set.
+	CacheDir string
+}
+
+// NewEmber returns a client for the Ember data embedded in the binary.
 func NewEmber() (Interface, error) {
-	data, err := data.GetEmberGridIntensity()
+	return NewEmberWithConfig(EmberConfig{})
+}
+
+// NewEmberWithConfig returns a client for the embedded Ember data and any
+// data files set in the config.
+func NewEmberWithConfig(config EmberConfig) (Interface, error) {
+	dataFile := config.DataFile
+	if dataFile == "" {
+		dataFile = findCacheFile(config.CacheDir, EmberCacheFileName)
+	}
+	monthlyDataFile := config.MonthlyDataFile
+	if monthlyDataFile == "" {
+		monthlyDataFile = findCacheFile(config.CacheDir, EmberMonthlyCacheFileName)
+	}
+
+	var emberData data.EmberData
+	var err error
+
+	if dataFile != "" {
+		emberData, err = data.LoadEmberGridIntensity(dataFile)
+	} else {
+		emberData, err = data.GetEmberGridIntensity()
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	var monthlyData data.EmberMonthlyData
+	if monthlyDataFile != "" {
+		monthlyData, err = data.LoadEmberMonthlyGridIntensity(monthlyDataFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	c := &EmberClient{
-		data: data,
+		data:    emberData,
+		monthly: monthlyData,
+		year:    config.Year,
 	}
 
 	return c, nil
 }
 
+// GetCarbonIntensity returns the latest monthly data for the location if
+// monthly data is loaded. Otherwise the yearly data is returned.
 func (a *EmberClient) GetCarbonIntensity(ctx context.Context, location string) ([]CarbonIntensity, error) {
-	location = strings.ToUpper(location)
-	result, ok := a.data[location]
+	if a.year == 0 {
+		code := data.AggregateCode(location)
+		if month, result, ok := a.monthly.Latest(code); ok {
+			return emberMonthlyCarbonIntensity(code, month, result), nil
+		}
+	}
+
+	return a.GetCarbonIntensityForYear(ctx, location, a.year)
+}
+
+// GetCarbonIntensityAt returns the carbon intensity for the month containing
+// the time if monthly data is loaded. Otherwise the data for the year
+// containing the time is returned.
+func (a *EmberClient) GetCarbonIntensityAt(ctx context.Context, location string, at time.Time) ([]CarbonIntensity, error) {
+	code := data.AggregateCode(location)
+
+	at = at.UTC()
+	month := time.Date(at.Year(), at.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	if result, ok := a.monthly[code][month]; ok {
+		return emberMonthlyCarbonIntensity(code, month, result), nil
+	}
+
+	return a.GetCarbonIntensityForYear(ctx, location, at.Year())
+}
+
+// GetCarbonIntensityQuery returns the data for the month or year containing
+// the query time. Only average emissions are available.
+func (a *EmberClient) GetCarbonIntensityQuery(ctx context.Context, query Query) ([]CarbonIntensity, error) {
+	err := query.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []CarbonIntensity
+	if query.At.IsZero() {
+		result, err = a.GetCarbonIntensity(ctx, query.Location)
+	} else {
+		result, err = a.GetCarbonIntensityAt(ctx, query.Location, query.At)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return filterQuery(Ember, query, result, false)
+}
+
+// GetCarbonIntensityForYear returns the carbon intensity for a location and
+// year. If year is 0 the latest year available for the location is used.
+// Locations are 2 or 3 char ISO country codes or aggregate codes such as EU or
+// WORLD. Aggregates can also be looked up by name e.g. North America.
+func (a *EmberClient) GetCarbonIntensityForYear(ctx context.Context, location string, year int) ([]CarbonIntensity, error) {
+	code := data.AggregateCode(location)
+
+	var result data.EmberGridIntensity
+	var ok bool
+
+	if year == 0 {
+		result, ok = a.data.Latest(code)
+	} else {
+		result, ok = a.data[code][year]
+	}
 	if !ok {
-		return nil, fmt.Errorf("location %q not found", location)
+		if _, found := a.data[code]; found {
+			return nil, &Error{
+				Provider: Ember,
+				Location: location,
+				Category: ErrorCategoryNotFound,
+				Err:      fmt.Errorf("no data for year %d", year),
+			}
+		}
+		return nil, newError(Ember, location, ErrInvalidLocation)
 	}
 
-	validFrom := time.Date(emberDataYear, 1, 1, 0, 0, 0, 0, time.UTC)
-	validTo := time.Date(emberDataYear, 12, 31, 23, 59, 0, 0, time.UTC)
+	validFrom := time.Date(result.Year, 1, 1, 0, 0, 0, 0, time.UTC)
+	validTo := time.Date(result.Year, 12, 31, 23, 59, 0, 0, time.UTC)
 
 	return []CarbonIntensity{
-		{
-			EmissionsType: AverageEmissionsType,
-			MetricType:    AbsoluteMetricType,
-			Provider:      Ember,
-			Location:      location,
-			Units:         GramsCO2EPerkWh,
-			ValidFrom:     validFrom,
-			ValidTo:       validTo,
-			Value:         result.EmissionsIntensityGCO2PerKWH,
-			IsEstimated:   true,
-		},
+		emberCarbonIntensity(code, validFrom, validTo, result.EmissionsIntensityGCO2PerKWH),
 	}, nil
 }
+
+// Aggregates returns the codes for aggregates of countries such as EU or
+// WORLD. These can be used as a default location when the country is not
+// known.
+func (a *EmberClient) Aggregates() []string {
+	return a.data.Aggregates()
+}
+
+// emberMonthlyCarbonIntensity returns the data for a month. It is valid from
+// the start of the month until the start of the next month.
+func emberMonthlyCarbonIntensity(location string, month time.Time, result data.EmberGridIntensity) []CarbonIntensity {
+	return []CarbonIntensity{
+		emberCarbonIntensity(location, month, month.AddDate(0, 1, 0), result.EmissionsIntensityGCO2PerKWH),
+	}
+}
+
+func emberCarbonIntensity(location string, validFrom, validTo time.Time, value float64) CarbonIntensity {
+	return CarbonIntensity{
+		EmissionsType: AverageEmissionsType,
+		MetricType:    AbsoluteMetricType,
+		Provider:      Ember,
+		Location:      location,
+		Units:         GramsCO2EPerkWh,
+		ValidFrom:     validFrom,
+		ValidTo:       validTo,
+		Value:         value,
+		IsEstimated:   true,
+	}
+}
+
+// findCacheFile returns the path of the file in the cache dir if it exists.
+func findCacheFile(cacheDir, fileName string) string {
+	if cacheDir == "" {
+		return ""
+	}
+
+	cacheFile := filepath.Join(cacheDir, fileName)
+	if _, err := os.Stat(cacheFile); err != nil {
+		return ""
+	}
+
+	return cacheFile
+}
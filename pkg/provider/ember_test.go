@@ -2,6 +2,7 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"reflect"
 	"testing"
 	"time"
@@ -69,10 +70,44 @@ func Test_GetGridIntensityForCountry(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:     "aggregate code",
+			location: "world",
+			result: []CarbonIntensity{
+				{
+					EmissionsType: "average",
+					MetricType:    "absolute",
+					Provider:      "Ember",
+					Location:      "WORLD",
+					Units:         "gCO2e per kWh",
+					ValidFrom:     time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+					ValidTo:       time.Date(2021, 12, 31, 23, 59, 0, 0, time.UTC),
+					Value:         442.3,
+					IsEstimated:   true,
+				},
+			},
+		},
+		{
+			name:     "aggregate name",
+			location: "North America",
+			result: []CarbonIntensity{
+				{
+					EmissionsType: "average",
+					MetricType:    "absolute",
+					Provider:      "Ember",
+					Location:      "NORTH_AMERICA",
+					Units:         "gCO2e per kWh",
+					ValidFrom:     time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+					ValidTo:       time.Date(2021, 12, 31, 23, 59, 0, 0, time.UTC),
+					Value:         345.38,
+					IsEstimated:   true,
+				},
+			},
+		},
 		{
 			name:        "invalid country code",
 			location:    "AAA",
-			expectedErr: "location \"AAA\" not found",
+			expectedErr: "Ember location \"AAA\": location is not supported by this provider",
 		},
 	}
 
@@ -104,3 +139,226 @@ func Test_GetGridIntensityForCountry(t *testing.T) {
 		})
 	}
 }
+
+func Test_Ember_DataFile(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		config   EmberConfig
+		location string
+		year     int
+		value    float64
+	}{
+		{
+			name: "latest year by default",
+			config: EmberConfig{
+				DataFile: "testdata/ember_yearly_long_format.csv",
+			},
+			location: "ES",
+			year:     2023,
+			value:    146.5,
+		},
+		{
+			name: "requested year",
+			config: EmberConfig{
+				DataFile: "testdata/ember_yearly_long_format.csv",
+				Year:     2022,
+			},
+			location: "ESP",
+			year:     2022,
+			value:    180.1,
+		},
+		{
+			name: "embedded year",
+			config: EmberConfig{
+				DataFile: "testdata/ember_yearly_long_format.csv",
+				Year:     2021,
+			},
+			location: "ESP",
+			year:     2021,
+			value:    193.737,
+		},
+		{
+			name: "country only in data file",
+			config: EmberConfig{
+				DataFile: "testdata/ember_yearly_long_format.csv",
+			},
+			location: "XKX",
+			year:     2023,
+			value:    985.3,
+		},
+		{
+			name:     "embedded data when no file in cache dir",
+			config:   EmberConfig{CacheDir: t.TempDir()},
+			location: "ESP",
+			year:     2021,
+			value:    193.737,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := NewEmberWithConfig(tc.config)
+			if err != nil {
+				t.Fatalf("Could not make provider: %s", err)
+			}
+
+			result, err := p.GetCarbonIntensity(ctx, tc.location)
+			if err != nil {
+				t.Fatalf("error == %#v want nil", err)
+			}
+
+			expected := []CarbonIntensity{
+				{
+					EmissionsType: "average",
+					MetricType:    "absolute",
+					Provider:      "Ember",
+					Location:      tc.location,
+					Units:         "gCO2e per kWh",
+					ValidFrom:     time.Date(tc.year, 1, 1, 0, 0, 0, 0, time.UTC),
+					ValidTo:       time.Date(tc.year, 12, 31, 23, 59, 0, 0, time.UTC),
+					Value:         tc.value,
+					IsEstimated:   true,
+				},
+			}
+			if !reflect.DeepEqual(expected, result) {
+				t.Errorf("want matching \n %s", cmp.Diff(result, expected))
+			}
+		})
+	}
+}
+
+func Test_Ember_Aggregates(t *testing.T) {
+	p, err := NewEmber()
+	if err != nil {
+		t.Fatalf("Could not make provider: %s", err)
+	}
+
+	expected := []string{
+		"AFRICA",
+		"ASIA",
+		"EU",
+		"EUROPE",
+		"G20",
+		"G7",
+		"LATIN_AMERICA_AND_CARIBBEAN",
+		"NORTH_AMERICA",
+		"OCEANIA",
+		"OECD",
+		"WORLD",
+	}
+	result := p.(*EmberClient).Aggregates()
+	if !reflect.DeepEqual(expected, result) {
+		t.Errorf("want matching \n %s", cmp.Diff(result, expected))
+	}
+}
+
+func Test_Ember_MonthlyData(t *testing.T) {
+	ctx := context.Background()
+
+	p, err := NewEmberWithConfig(EmberConfig{
+		MonthlyDataFile: "testdata/ember_monthly_long_format.csv",
+	})
+	if err != nil {
+		t.Fatalf("Could not make provider: %s", err)
+	}
+	e := p.(*EmberClient)
+
+	tests := []struct {
+		name      string
+		location  string
+		at        time.Time
+		validFrom time.Time
+		validTo   time.Time
+		value     float64
+	}{
+		{
+			name:      "month containing time",
+			location:  "NO",
+			at:        time.Date(2023, 1, 15, 12, 0, 0, 0, time.UTC),
+			validFrom: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			validTo:   time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC),
+			value:     30.5,
+		},
+		{
+			name:      "aggregate",
+			location:  "WORLD",
+			at:        time.Date(2023, 2, 28, 23, 59, 0, 0, time.UTC),
+			validFrom: time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC),
+			validTo:   time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC),
+			value:     470.2,
+		},
+		{
+			name:      "yearly data when month not found",
+			location:  "NOR",
+			at:        time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC),
+			validFrom: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+			validTo:   time.Date(2021, 12, 31, 23, 59, 0, 0, time.UTC),
+			value:     26.131,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := e.GetCarbonIntensityAt(ctx, tc.location, tc.at)
+			if err != nil {
+				t.Fatalf("error == %#v want nil", err)
+			}
+
+			expected := []CarbonIntensity{
+				{
+					EmissionsType: "average",
+					MetricType:    "absolute",
+					Provider:      "Ember",
+					Location:      tc.location,
+					Units:         "gCO2e per kWh",
+					ValidFrom:     tc.validFrom,
+					ValidTo:       tc.validTo,
+					Value:         tc.value,
+					IsEstimated:   true,
+				},
+			}
+			if !reflect.DeepEqual(expected, result) {
+				t.Errorf("want matching \n %s", cmp.Diff(result, expected))
+			}
+		})
+	}
+
+	// The latest month is returned by default.
+	result, err := p.GetCarbonIntensity(ctx, "NOR")
+	if err != nil {
+		t.Fatalf("error == %#v want nil", err)
+	}
+	if len(result) != 1 || result[0].Value != 28.1 {
+		t.Errorf("expected latest month value 28.1 got %#v", result)
+	}
+}
+
+func Test_Ember_Query(t *testing.T) {
+	ctx := context.Background()
+
+	p, err := NewEmber()
+	if err != nil {
+		t.Fatalf("Could not make provider: %s", err)
+	}
+
+	query := Query{
+		Location:      "NOR",
+		EmissionsType: AverageEmissionsType,
+		At:            time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC),
+	}
+	result, err := GetCarbonIntensityQuery(ctx, p, query)
+	if err != nil {
+		t.Fatalf("error == %#v want nil", err)
+	}
+	if len(result) != 1 || result[0].ValidFrom.Year() != 2021 {
+		t.Errorf("expected data for 2021 got %#v", result)
+	}
+
+	query.EmissionsType = MarginalEmissionsType
+	_, err = GetCarbonIntensityQuery(ctx, p, query)
+	if !errors.Is(err, ErrNoMarginalIntensityPresent) {
+		t.Errorf("expected %v got %v", ErrNoMarginalIntensityPresent, err)
+	}
+}
@@ -0,0 +1,184 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type EnergiDataServiceClient struct {
+	*httpClient
+
+	apiURL string
+	// now returns the current time and is replaced in tests.
+	now func() time.Time
+}
+
+type EnergiDataServiceConfig struct {
+	Client    *http.Client
+	Retry     RetryConfig
+	RateLimit RateLimitConfig
+	Logger    *slog.Logger
+	APIURL    string
+}
+
+func NewEnergiDataService(config EnergiDataServiceConfig) (Interface, error) {
+	if config.Client == nil {
+		config.Client = &http.Client{
+			Timeout: 5 * time.Second,
+		}
+	}
+	if config.APIURL == "" {
+		config.APIURL = "https://api.energidataservice.dk"
+	}
+
+	c := &EnergiDataServiceClient{
+		httpClient: newHTTPClient(config.Client, config.Retry, config.RateLimit, newLogger(config.Logger, EnergiDataService)),
+		apiURL:     config.APIURL,
+		now:        time.Now,
+	}
+
+	return c, nil
+}
+
+// GetCarbonIntensity returns the most recent realised value from the CO2Emis
+// dataset followed by the forecast values from the CO2EmisProg dataset
+// starting with the 5 minute slot containing the current time.
+func (e *EnergiDataServiceClient) GetCarbonIntensity(ctx context.Context, location string) ([]CarbonIntensity, error) {
+	return e.coalesce(ctx, EnergiDataService, location, e.getCarbonIntensity)
+}
+
+func (e *EnergiDataServiceClient) getCarbonIntensity(ctx context.Context, location string) ([]CarbonIntensity, error) {
+	location = strings.ToUpper(location)
+
+	var priceArea string
+	switch location {
+	case "DK-DK1":
+		priceArea = "DK1"
+	case "DK-DK2":
+		priceArea = "DK2"
+	default:
+		return nil, ErrInvalidLocation
+	}
+
+	realised, err := e.getDataset(ctx, "CO2Emis", priceArea, url.Values{
+		"start": []string{"now-P1D"},
+		"sort":  []string{"Minutes5UTC DESC"},
+		"limit": []string{"1"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	forecast, err := e.getDataset(ctx, "CO2EmisProg", priceArea, url.Values{
+		"start": []string{"now-PT5M"},
+		"end":   []string{"now+P1D"},
+		"sort":  []string{"Minutes5UTC ASC"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(realised) == 0 && len(forecast) == 0 {
+		return nil, ErrNoResponse
+	}
+
+	now := e.now()
+	var result []CarbonIntensity
+
+	for _, record := range realised {
+		point, err := record.toCarbonIntensity(location, false)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *point)
+	}
+
+	for _, record := range forecast {
+		point, err := record.toCarbonIntensity(location, true)
+		if err != nil {
+			return nil, err
+		}
+		// Skip forecast slots that have already finished.
+		if !point.ValidTo.After(now) {
+			continue
+		}
+		result = append(result, *point)
+	}
+
+	return result, nil
+}
+
+func (e *EnergiDataServiceClient) getDataset(ctx context.Context, dataset, priceArea string, params url.Values) ([]energiDataServiceRecord, error) {
+	datasetURL, err := e.datasetURL(dataset, priceArea, params)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, datasetURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := e.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errBadStatus(resp)
+	}
+
+	respObj := &energiDataServiceResponse{}
+	err = json.NewDecoder(resp.Body).Decode(respObj)
+	if err != nil {
+		return nil, err
+	}
+
+	return respObj.Records, nil
+}
+
+func (e *EnergiDataServiceClient) datasetURL(dataset, priceArea string, params url.Values) (string, error) {
+	params.Set("filter", fmt.Sprintf(`{"PriceArea":["%s"]}`, priceArea))
+	params.Set("columns", "Minutes5UTC,PriceArea,CO2Emission")
+
+	return buildURL(e.apiURL, fmt.Sprintf("/dataset/%s?%s", dataset, params.Encode()))
+}
+
+func (r *energiDataServiceRecord) toCarbonIntensity(location string, isEstimated bool) (*CarbonIntensity, error) {
+	// Minutes5UTC does not include a time zone.
+	validFrom, err := time.Parse("2006-01-02T15:04:05", r.Minutes5UTC)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CarbonIntensity{
+		EmissionsType: AverageEmissionsType,
+		MetricType:    AbsoluteMetricType,
+		Provider:      EnergiDataService,
+		Location:      location,
+		Units:         GramsCO2EPerkWh,
+		ValidFrom:     validFrom,
+		ValidTo:       validFrom.Add(5 * time.Minute),
+		Value:         r.CO2Emission,
+		IsEstimated:   isEstimated,
+	}, nil
+}
+
+type energiDataServiceResponse struct {
+	Total   int                       `json:"total"`
+	Dataset string                    `json:"dataset"`
+	Records []energiDataServiceRecord `json:"records"`
+}
+
+type energiDataServiceRecord struct {
+	Minutes5UTC string  `json:"Minutes5UTC"`
+	PriceArea   string  `json:"PriceArea"`
+	CO2Emission float64 `json:"CO2Emission"`
+}
@@ -0,0 +1,107 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var MockEnergiDataServiceResponse = `{
+	"total": %d,
+	"dataset": "%s",
+	"records": [%s]
+}`
+
+var MockEnergiDataServiceRecord = `{
+	"Minutes5UTC": "%s",
+	"PriceArea": "DK1",
+	"CO2Emission": %g
+}`
+
+func Test_EnergiDataService_SimpleRequest(t *testing.T) {
+	layout := "2006-01-02T15:04:05"
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	realisedTime := now.Add(-10 * time.Minute)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("filter") != `{"PriceArea":["DK1"]}` {
+			t.Errorf("unexpected filter %#q", r.URL.Query().Get("filter"))
+		}
+
+		switch r.URL.Path {
+		case "/dataset/CO2Emis":
+			record := fmt.Sprintf(MockEnergiDataServiceRecord, realisedTime.Format(layout), 94.0)
+			fmt.Fprintf(w, MockEnergiDataServiceResponse, 1, "CO2Emis", record)
+		case "/dataset/CO2EmisProg":
+			records := fmt.Sprintf(MockEnergiDataServiceRecord, now.Add(-5*time.Minute).Format(layout), 99.0) + "," +
+				fmt.Sprintf(MockEnergiDataServiceRecord, now.Format(layout), 101.0) + "," +
+				fmt.Sprintf(MockEnergiDataServiceRecord, now.Add(5*time.Minute).Format(layout), 103.5)
+			fmt.Fprintf(w, MockEnergiDataServiceResponse, 3, "CO2EmisProg", records)
+		default:
+			t.Errorf("unknown path %#q", r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	c := EnergiDataServiceConfig{
+		APIURL: ts.URL,
+	}
+	e, err := NewEnergiDataService(c)
+	if err != nil {
+		t.Errorf("Could not make provider: %s", err)
+		return
+	}
+	e.(*EnergiDataServiceClient).now = func() time.Time {
+		return now.Add(2 * time.Minute)
+	}
+
+	res, err := e.GetCarbonIntensity(context.Background(), "DK-DK1")
+	if err != nil {
+		t.Fatalf("got error on GetCarbonIntensity: %s", err)
+	}
+
+	expected := []CarbonIntensity{
+		{
+			EmissionsType: "average",
+			MetricType:    "absolute",
+			Provider:      "EnergiDataService",
+			Location:      "DK-DK1",
+			Units:         "gCO2e per kWh",
+			ValidFrom:     realisedTime,
+			ValidTo:       realisedTime.Add(5 * time.Minute),
+			Value:         94,
+			IsEstimated:   false,
+		},
+		{
+			EmissionsType: "average",
+			MetricType:    "absolute",
+			Provider:      "EnergiDataService",
+			Location:      "DK-DK1",
+			Units:         "gCO2e per kWh",
+			ValidFrom:     now,
+			ValidTo:       now.Add(5 * time.Minute),
+			Value:         101,
+			IsEstimated:   true,
+		},
+		{
+			EmissionsType: "average",
+			MetricType:    "absolute",
+			Provider:      "EnergiDataService",
+			Location:      "DK-DK1",
+			Units:         "gCO2e per kWh",
+			ValidFrom:     now.Add(5 * time.Minute),
+			ValidTo:       now.Add(10 * time.Minute),
+			Value:         103.5,
+			IsEstimated:   true,
+		},
+	}
+	if !reflect.DeepEqual(expected, res) {
+		t.Errorf("want matching \n %s", cmp.Diff(res, expected))
+	}
+}
@@ -0,0 +1,408 @@
+package provider
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// entsoeBiddingZones maps friendly location codes to ENTSO-E bidding zone
+// EIC codes.
+var entsoeBiddingZones = map[string]string{
+	"AT":      "10YAT-APG------L",
+	"BE":      "10YBE----------2",
+	"BG":      "10YCA-BULGARIA-R",
+	"CH":      "10YCH-SWISSGRIDZ",
+	"CZ":      "10YCZ-CEPS-----N",
+	"DE":      "10Y1001A1001A82H",
+	"DK-DK1":  "10YDK-1--------W",
+	"DK-DK2":  "10YDK-2--------M",
+	"EE":      "10Y1001A1001A39I",
+	"ES":      "10YES-REE------0",
+	"FI":      "10YFI-1--------U",
+	"FR":      "10YFR-RTE------C",
+	"GR":      "10YGR-HTSO-----Y",
+	"HR":      "10YHR-HEP------M",
+	"HU":      "10YHU-MAVIR----U",
+	"IE":      "10Y1001A1001A59C",
+	"IT-CNOR": "10Y1001A1001A70O",
+	"IT-CSUD": "10Y1001A1001A71M",
+	"IT-NORD": "10Y1001A1001A73I",
+	"IT-SARD": "10Y1001A1001A74G",
+	"IT-SICI": "10Y1001A1001A75E",
+	"IT-SUD":  "10Y1001A1001A788",
+	"LT":      "10YLT-1001A0008Q",
+	"LV":      "10YLV-1001A00074",
+	"NL":      "10YNL----------L",
+	"NO-NO1":  "10YNO-1--------2",
+	"NO-NO2":  "10YNO-2--------T",
+	"NO-NO3":  "10YNO-3--------J",
+	"NO-NO4":  "10YNO-4--------9",
+	"NO-NO5":  "10Y1001A1001A48H",
+	"PL":      "10YPL-AREA-----S",
+	"PT":      "10YPT-REN------W",
+	"RO":      "10YRO-TEL------P",
+	"SE-SE1":  "10Y1001A1001A44P",
+	"SE-SE2":  "10Y1001A1001A45N",
+	"SE-SE3":  "10Y1001A1001A46L",
+	"SE-SE4":  "10Y1001A1001A47J",
+	"SI":      "10YSI-ELES-----O",
+	"SK":      "10YSK-SEPS-----K",
+}
+
+// DefaultENTSOEEmissionFactors are lifecycle emission factors in gCO2e per
+// kWh for each ENTSO-E production type (psrType). They are based on the
+// IPCC 2014 median values.
+var DefaultENTSOEEmissionFactors = map[string]float64{
+	"B01": 230, // Biomass
+	"B02": 820, // Fossil Brown coal/Lignite
+	"B03": 490, // Fossil Coal-derived gas
+	"B04": 490, // Fossil Gas
+	"B05": 820, // Fossil Hard coal
+	"B06": 650, // Fossil Oil
+	"B07": 650, // Fossil Oil shale
+	"B08": 820, // Fossil Peat
+	"B09": 38,  // Geothermal
+	"B10": 24,  // Hydro Pumped Storage
+	"B11": 24,  // Hydro Run-of-river and poundage
+	"B12": 24,  // Hydro Water Reservoir
+	"B13": 24,  // Marine
+	"B14": 12,  // Nuclear
+	"B15": 30,  // Other renewable
+	"B16": 45,  // Solar
+	"B17": 580, // Waste
+	"B18": 12,  // Wind Offshore
+	"B19": 11,  // Wind Onshore
+	"B20": 700, // Other
+}
+
+type ENTSOEClient struct {
+	*httpClient
+
+	apiURL          string
+	token           string
+	emissionFactors map[string]float64
+}
+
+type ENTSOEConfig struct {
+	Client    *http.Client
+	Retry     RetryConfig
+	RateLimit RateLimitConfig
+	Logger    *slog.Logger
+	APIURL    string
+	Token     string
+	// EmissionFactors overrides the default emission factors in gCO2e per
+	// kWh keyed by ENTSO-E production type e.g. B04 for Fossil Gas.
+	EmissionFactors map[string]float64
+}
+
+func NewENTSOE(config ENTSOEConfig) (Interface, error) {
+	if config.Client == nil {
+		config.Client = &http.Client{
+			Timeout: 10 * time.Second,
+		}
+	}
+	if config.APIURL == "" {
+		config.APIURL = "https://web-api.tp.entsoe.eu/api"
+	}
+
+	c := &ENTSOEClient{
+		httpClient:      newHTTPClient(config.Client, config.Retry, config.RateLimit, newLogger(config.Logger, ENTSOE)),
+		apiURL:          config.APIURL,
+		token:           config.Token,
+		emissionFactors: mergeEmissionFactors(DefaultENTSOEEmissionFactors, config.EmissionFactors),
+	}
+
+	return c, nil
+}
+
+func (e *ENTSOEClient) GetCarbonIntensity(ctx context.Context, location string) ([]CarbonIntensity, error) {
+	return e.coalesce(ctx, ENTSOE, location, e.getCarbonIntensity)
+}
+
+func (e *ENTSOEClient) getCarbonIntensity(ctx context.Context, location string) ([]CarbonIntensity, error) {
+	location, eic, err := entsoeLookupZone(location)
+	if err != nil {
+		return nil, err
+	}
+
+	periodEnd := time.Now().UTC().Truncate(time.Hour).Add(time.Hour)
+	periodStart := periodEnd.Add(-24 * time.Hour)
+
+	generationURL, err := e.generationURL(eic, periodStart, periodEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, generationURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	query := req.URL.Query()
+	query.Set("securityToken", e.token)
+	req.URL.RawQuery = query.Encode()
+
+	resp, err := e.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errBadStatus(resp)
+	}
+
+	doc := &entsoeMarketDocument{}
+	err = xml.NewDecoder(resp.Body).Decode(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	slots, err := doc.generationSlots()
+	if err != nil {
+		return nil, err
+	}
+	if len(slots) == 0 {
+		return nil, ErrNoResponse
+	}
+
+	// Use the most recent slot where all the production types have been
+	// reported.
+	generation := make([]map[string]float64, len(slots))
+	for i, slot := range slots {
+		generation[i] = slot.generation
+	}
+	latest := slots[latestCompletePeriod(generation)]
+
+	// Unknown production types use the factor for B20 Other.
+	value, ok := averageIntensity(latest.generation, e.emissionFactors, "B20")
+	if !ok {
+		return nil, ErrNoResponse
+	}
+
+	return []CarbonIntensity{
+		{
+			EmissionsType: AverageEmissionsType,
+			MetricType:    AbsoluteMetricType,
+			Provider:      ENTSOE,
+			Location:      location,
+			Units:         GramsCO2EPerkWh,
+			ValidFrom:     latest.validFrom,
+			ValidTo:       latest.validTo,
+			Value:         value,
+			IsEstimated:   false,
+		},
+	}, nil
+}
+
+func (e *ENTSOEClient) generationURL(eic string, periodStart, periodEnd time.Time) (string, error) {
+	layout := "200601021504"
+
+	params := url.Values{}
+	params.Set("documentType", "A75")
+	params.Set("processType", "A16")
+	params.Set("in_Domain", eic)
+	params.Set("periodStart", periodStart.Format(layout))
+	params.Set("periodEnd", periodEnd.Format(layout))
+
+	return buildURL(e.apiURL, "?"+params.Encode())
+}
+
+// entsoeLookupZone returns the friendly location code and EIC code for a
+// location. Both friendly codes and EIC codes are accepted.
+func entsoeLookupZone(location string) (string, string, error) {
+	location = strings.ToUpper(location)
+
+	if eic, ok := entsoeBiddingZones[location]; ok {
+		return location, eic, nil
+	}
+	for code, eic := range entsoeBiddingZones {
+		if eic == location {
+			return code, eic, nil
+		}
+	}
+
+	return "", "", ErrInvalidLocation
+}
+
+type entsoeSlot struct {
+	validFrom  time.Time
+	validTo    time.Time
+	generation map[string]float64
+}
+
+// generationSlots returns the generation per production type for each time
+// slot in the document ordered by time.
+func (d *entsoeMarketDocument) generationSlots() ([]*entsoeSlot, error) {
+	slots := map[time.Time]*entsoeSlot{}
+
+	for _, series := range d.TimeSeries {
+		// Series with an out bidding zone are consumption e.g. pumped
+		// storage, so only generation is included.
+		if series.InBiddingZone == "" {
+			continue
+		}
+
+		for _, period := range series.Periods {
+			points, err := period.points(series.CurveType)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, p := range points {
+				slot, ok := slots[p.validFrom]
+				if !ok {
+					slot = &entsoeSlot{
+						validFrom:  p.validFrom,
+						validTo:    p.validTo,
+						generation: map[string]float64{},
+					}
+					slots[p.validFrom] = slot
+				}
+				slot.generation[series.PSRType] += p.quantity
+			}
+		}
+	}
+
+	result := make([]*entsoeSlot, 0, len(slots))
+	for _, slot := range slots {
+		result = append(result, slot)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].validFrom.Before(result[j].validFrom)
+	})
+
+	return result, nil
+}
+
+type entsoePoint struct {
+	validFrom time.Time
+	validTo   time.Time
+	quantity  float64
+}
+
+// points returns the points in the period with their time intervals. For
+// curve type A03 positions may be omitted and the previous quantity applies.
+func (p *entsoePeriod) points(curveType string) ([]entsoePoint, error) {
+	layout := "2006-01-02T15:04Z"
+	start, err := time.Parse(layout, p.TimeInterval.Start)
+	if err != nil {
+		return nil, err
+	}
+	end, err := time.Parse(layout, p.TimeInterval.End)
+	if err != nil {
+		return nil, err
+	}
+	resolution, err := parseISODuration(p.Resolution)
+	if err != nil {
+		return nil, err
+	}
+
+	quantities := make(map[int]float64, len(p.Points))
+	for _, point := range p.Points {
+		quantities[point.Position] = point.Quantity
+	}
+
+	var result []entsoePoint
+	var quantity float64
+	var found bool
+
+	position := 1
+	for validFrom := start; validFrom.Before(end); validFrom = validFrom.Add(resolution) {
+		q, ok := quantities[position]
+		position++
+
+		if ok {
+			quantity = q
+			found = true
+		} else if curveType != "A03" || !found {
+			continue
+		}
+
+		result = append(result, entsoePoint{
+			validFrom: validFrom,
+			validTo:   validFrom.Add(resolution),
+			quantity:  quantity,
+		})
+	}
+
+	return result, nil
+}
+
+// parseISODuration parses the subset of ISO 8601 durations used for
+// resolutions e.g. PT15M, PT60M or PT1H.
+func parseISODuration(value string) (time.Duration, error) {
+	if !strings.HasPrefix(value, "PT") {
+		return 0, fmt.Errorf("unsupported resolution %q", value)
+	}
+
+	var result time.Duration
+	var number string
+
+	for _, r := range value[2:] {
+		var unit time.Duration
+
+		switch r {
+		case 'H':
+			unit = time.Hour
+		case 'M':
+			unit = time.Minute
+		case 'S':
+			unit = time.Second
+		default:
+			if r < '0' || r > '9' {
+				return 0, fmt.Errorf("unsupported resolution %q", value)
+			}
+			number += string(r)
+			continue
+		}
+
+		n, err := strconv.Atoi(number)
+		if err != nil {
+			return 0, fmt.Errorf("unsupported resolution %q", value)
+		}
+		result += time.Duration(n) * unit
+		number = ""
+	}
+	if number != "" || result <= 0 {
+		return 0, fmt.Errorf("unsupported resolution %q", value)
+	}
+
+	return result, nil
+}
+
+type entsoeMarketDocument struct {
+	TimeSeries []entsoeTimeSeries `xml:"TimeSeries"`
+}
+
+type entsoeTimeSeries struct {
+	InBiddingZone  string         `xml:"inBiddingZone_Domain.mRID"`
+	OutBiddingZone string         `xml:"outBiddingZone_Domain.mRID"`
+	CurveType      string         `xml:"curveType"`
+	PSRType        string         `xml:"MktPSRType>psrType"`
+	Periods        []entsoePeriod `xml:"Period"`
+}
+
+type entsoePeriod struct {
+	TimeInterval entsoeTimeInterval `xml:"timeInterval"`
+	Resolution   string             `xml:"resolution"`
+	Points       []entsoeXMLPoint   `xml:"Point"`
+}
+
+type entsoeTimeInterval struct {
+	Start string `xml:"start"`
+	End   string `xml:"end"`
+}
+
+type entsoeXMLPoint struct {
+	Position int     `xml:"position"`
+	Quantity float64 `xml:"quantity"`
+}
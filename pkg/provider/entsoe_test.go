@@ -0,0 +1,142 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// MockENTSOEPartialTimeSeries has nuclear generation for an hour after the
+// fixture where the other production types have not been reported yet.
+var MockENTSOEPartialTimeSeries = `<TimeSeries>
+		<mRID>5</mRID>
+		<businessType>A01</businessType>
+		<objectAggregation>A08</objectAggregation>
+		<inBiddingZone_Domain.mRID codingScheme="A01">10YFR-RTE------C</inBiddingZone_Domain.mRID>
+		<quantity_Measure_Unit.name>MAW</quantity_Measure_Unit.name>
+		<curveType>A01</curveType>
+		<MktPSRType>
+			<psrType>B14</psrType>
+		</MktPSRType>
+		<Period>
+			<timeInterval>
+				<start>2023-03-01T02:00Z</start>
+				<end>2023-03-01T03:00Z</end>
+			</timeInterval>
+			<resolution>PT60M</resolution>
+			<Point>
+				<position>1</position>
+				<quantity>42000</quantity>
+			</Point>
+		</Period>
+	</TimeSeries>
+</GL_MarketDocument>`
+
+func makeENTSOETestServer(t *testing.T) *httptest.Server {
+	fixture, err := os.ReadFile("testdata/entsoe_a75.xml")
+	if err != nil {
+		t.Fatalf("could not read fixture: %s", err)
+	}
+
+	return makeENTSOETestServerWithFixture(t, fixture)
+}
+
+func makeENTSOETestServerWithFixture(t *testing.T, fixture []byte) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("securityToken") != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if query.Get("documentType") != "A75" || query.Get("in_Domain") != "10YFR-RTE------C" {
+			t.Errorf("unexpected query %#q", r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write(fixture)
+	}))
+}
+
+func Test_ENTSOE_SimpleRequest(t *testing.T) {
+	ts := makeENTSOETestServer(t)
+	defer ts.Close()
+
+	c := ENTSOEConfig{
+		APIURL: ts.URL,
+		Token:  "token",
+	}
+	e, err := NewENTSOE(c)
+	if err != nil {
+		t.Errorf("Could not make provider: %s", err)
+		return
+	}
+
+	// Both friendly location codes and EIC codes are supported.
+	for _, location := range []string{"FR", "10YFR-RTE------C"} {
+		res, err := e.GetCarbonIntensity(context.Background(), location)
+		if err != nil {
+			t.Fatalf("got error on GetCarbonIntensity: %s", err)
+		}
+
+		expected := []CarbonIntensity{
+			{
+				EmissionsType: "average",
+				MetricType:    "absolute",
+				Provider:      "ENTSOE",
+				Location:      "FR",
+				Units:         "gCO2e per kWh",
+				ValidFrom:     time.Date(2023, 3, 1, 1, 0, 0, 0, time.UTC),
+				ValidTo:       time.Date(2023, 3, 1, 2, 0, 0, 0, time.UTC),
+				// (41000*12 + 4000*490 + 5000*11) / 50000
+				Value:       50.14,
+				IsEstimated: false,
+			},
+		}
+		if !reflect.DeepEqual(expected, res) {
+			t.Errorf("want matching \n %s", cmp.Diff(res, expected))
+		}
+	}
+
+	_, err = e.GetCarbonIntensity(context.Background(), "XX")
+	if !errors.Is(err, ErrInvalidLocation) {
+		t.Errorf("expected %v got %v", ErrInvalidLocation, err)
+	}
+}
+
+func Test_ENTSOE_PartialSlot(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/entsoe_a75.xml")
+	if err != nil {
+		t.Fatalf("could not read fixture: %s", err)
+	}
+	fixture = bytes.Replace(fixture, []byte("</GL_MarketDocument>"), []byte(MockENTSOEPartialTimeSeries), 1)
+
+	ts := makeENTSOETestServerWithFixture(t, fixture)
+	defer ts.Close()
+
+	c := ENTSOEConfig{
+		APIURL: ts.URL,
+		Token:  "token",
+	}
+	e, err := NewENTSOE(c)
+	if err != nil {
+		t.Fatalf("Could not make provider: %s", err)
+	}
+
+	res, err := e.GetCarbonIntensity(context.Background(), "FR")
+	if err != nil {
+		t.Fatalf("got error on GetCarbonIntensity: %s", err)
+	}
+
+	// Only nuclear has been reported for the final hour so the hour before
+	// it is used.
+	if len(res) != 1 || !res[0].ValidFrom.Equal(time.Date(2023, 3, 1, 1, 0, 0, 0, time.UTC)) || res[0].Value != 50.14 {
+		t.Errorf("expected value for complete slot got %#v", res)
+	}
+}
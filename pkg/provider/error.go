@@ -1,29 +1,200 @@
 package provider
 
 import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
 var (
 	ErrInvalidLocation            error = errors.New("location is not supported by this provider")
+	ErrNoAbsoluteIntensityPresent error = errors.New("no absolute intensity present")
+	ErrNoAverageIntensityPresent  error = errors.New("no average intensity present")
+	ErrNoIntensityPresentForTime  error = errors.New("no intensity present for time")
 	ErrNoMarginalIntensityPresent error = errors.New("no marginal intensity present")
 	ErrNoRelativeIntensityPresent error = errors.New("no relative intensity present")
 	ErrNoResponse                 error = errors.New("no data was received in response, try again later")
+	ErrQuotaExceeded              error = errors.New("request quota exceeded")
 	ErrUnknownResponse            error = errors.New("unknown index received")
 	ErrReceivedNon200Status       error = errors.New("received non-200 status")
 	ErrReceived403Forbidden       error = errors.New("received 403 forbidden")
 )
 
+// ErrorCategory is the type of failure of a request to a provider.
+type ErrorCategory string
+
+const (
+	// ErrorCategoryAuth is used when the credentials are missing or invalid.
+	ErrorCategoryAuth ErrorCategory = "auth"
+	// ErrorCategoryNotFound is used when the location or the data for it is
+	// not found.
+	ErrorCategoryNotFound ErrorCategory = "not_found"
+	// ErrorCategoryRateLimited is used when the provider or the client rate
+	// limit or quota has been exceeded.
+	ErrorCategoryRateLimited ErrorCategory = "rate_limited"
+	// ErrorCategoryUpstream is used for network errors, errors returned by
+	// the provider and when no data is returned.
+	ErrorCategoryUpstream ErrorCategory = "upstream"
+	// ErrorCategoryParse is used when the response could not be parsed.
+	ErrorCategoryParse ErrorCategory = "parse"
+)
+
+// Error is returned by providers when getting carbon intensity data fails.
+// Use errors.As to access it and errors.Is to check for the wrapped errors
+// such as ErrInvalidLocation.
+type Error struct {
+	Provider string
+	Location string
+	Category ErrorCategory
+	// StatusCode is the HTTP status code if a response was received.
+	StatusCode int
+	// Body is the response body for unsuccessful HTTP responses.
+	Body string
+	// Attempts is the number of requests made if the request was retried.
+	Attempts int
+	Err      error
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+
+	b.WriteString(e.Provider)
+	if e.Location != "" {
+		fmt.Fprintf(&b, " location %q", e.Location)
+	}
+	if e.Attempts > 1 {
+		fmt.Fprintf(&b, " after %d attempts", e.Attempts)
+	}
+	if e.StatusCode != 0 {
+		fmt.Fprintf(&b, ": %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+		if e.Body != "" {
+			fmt.Fprintf(&b, " - %s", e.Body)
+		}
+	}
+	if e.Err != nil {
+		fmt.Fprintf(&b, ": %s", e.Err)
+	}
+
+	return strings.TrimPrefix(b.String(), ": ")
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// newError adds the provider and location to the *Error in err. If err does
+// not contain an *Error one is created with the category set from err.
+func newError(provider, location string, err error) error {
+	var e *Error
+	if !errors.As(err, &e) {
+		e = &Error{Err: err}
+		err = e
+	}
+
+	if e.Provider == "" {
+		e.Provider = provider
+	}
+	if e.Location == "" {
+		e.Location = location
+	}
+	if e.Category == "" {
+		e.Category = errorCategory(e.Err)
+	}
+	if e.StatusCode == 0 && errors.Is(e.Err, ErrReceived403Forbidden) {
+		e.StatusCode = http.StatusForbidden
+	}
+
+	return err
+}
+
+// batchErrors returns an error for each location when a request for multiple
+// locations fails. The *Error in err is copied so it is not shared.
+func batchErrors(provider string, locations []string, err error) map[string]error {
+	errs := make(map[string]error, len(locations))
+
+	for _, location := range locations {
+		var e *Error
+		if errors.As(err, &e) {
+			copied := *e
+			errs[location] = newError(provider, location, &copied)
+		} else {
+			errs[location] = newError(provider, location, err)
+		}
+	}
+
+	return errs
+}
+
+// errorCategory returns the category for errors that are not HTTP errors.
+func errorCategory(err error) ErrorCategory {
+	var jsonSyntaxErr *json.SyntaxError
+	var jsonTypeErr *json.UnmarshalTypeError
+	var xmlSyntaxErr *xml.SyntaxError
+	var csvErr *csv.ParseError
+	var numErr *strconv.NumError
+	var timeErr *time.ParseError
+
+	switch {
+	case errors.Is(err, ErrInvalidLocation),
+		errors.Is(err, ErrNoAbsoluteIntensityPresent),
+		errors.Is(err, ErrNoAverageIntensityPresent),
+		errors.Is(err, ErrNoIntensityPresentForTime),
+		errors.Is(err, ErrNoMarginalIntensityPresent),
+		errors.Is(err, ErrNoRelativeIntensityPresent):
+		return ErrorCategoryNotFound
+	case errors.Is(err, ErrQuotaExceeded):
+		return ErrorCategoryRateLimited
+	case errors.Is(err, ErrReceived403Forbidden):
+		return ErrorCategoryAuth
+	case errors.Is(err, io.ErrUnexpectedEOF),
+		errors.As(err, &jsonSyntaxErr),
+		errors.As(err, &jsonTypeErr),
+		errors.As(err, &xmlSyntaxErr),
+		errors.As(err, &csvErr),
+		errors.As(err, &numErr),
+		errors.As(err, &timeErr):
+		return ErrorCategoryParse
+	}
+
+	return ErrorCategoryUpstream
+}
+
+// statusCategory returns the category for an unsuccessful HTTP status code.
+func statusCategory(statusCode int) ErrorCategory {
+	switch statusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrorCategoryAuth
+	case http.StatusNotFound:
+		return ErrorCategoryNotFound
+	case http.StatusTooManyRequests:
+		return ErrorCategoryRateLimited
+	}
+
+	return ErrorCategoryUpstream
+}
+
 func errBadStatus(resp *http.Response) error {
+	var body string
+
 	data, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		err = fmt.Errorf("could not read error response: %w", err)
+		body = fmt.Sprintf("could not read error response: %s", err)
 	} else {
-		err = errors.New(string(data))
+		body = strings.TrimSpace(string(data))
 	}
 
-	return fmt.Errorf("%s - %s: %w", resp.Status, err, ErrReceivedNon200Status)
+	return &Error{
+		Category:   statusCategory(resp.StatusCode),
+		StatusCode: resp.StatusCode,
+		Body:       body,
+		Err:        ErrReceivedNon200Status,
+	}
 }
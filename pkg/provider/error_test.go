@@ -0,0 +1,141 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func Test_Error_Categories(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		category   ErrorCategory
+		attempts   int
+	}{
+		{
+			name:       "unauthorized",
+			statusCode: http.StatusUnauthorized,
+			body:       "invalid token",
+			category:   ErrorCategoryAuth,
+		},
+		{
+			name:       "not found",
+			statusCode: http.StatusNotFound,
+			body:       "zone not found",
+			category:   ErrorCategoryNotFound,
+		},
+		{
+			name:       "rate limited",
+			statusCode: http.StatusTooManyRequests,
+			body:       "slow down",
+			category:   ErrorCategoryRateLimited,
+			attempts:   2,
+		},
+		{
+			name:       "upstream",
+			statusCode: http.StatusInternalServerError,
+			body:       "internal error",
+			category:   ErrorCategoryUpstream,
+			attempts:   2,
+		},
+		{
+			name:       "parse",
+			statusCode: http.StatusOK,
+			body:       "{",
+			category:   ErrorCategoryParse,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.statusCode)
+				fmt.Fprintln(w, tc.body)
+			}))
+			defer ts.Close()
+
+			c := ElectricityMapsConfig{
+				APIURL: ts.URL,
+				Token:  "token",
+				Retry: RetryConfig{
+					MaxAttempts:    2,
+					InitialBackoff: time.Millisecond,
+				},
+			}
+			a, err := NewElectricityMaps(c)
+			if err != nil {
+				t.Fatalf("Could not make provider: %s", err)
+			}
+
+			_, err = a.GetCarbonIntensity(context.Background(), "IN-KA")
+
+			var providerErr *Error
+			if !errors.As(err, &providerErr) {
+				t.Fatalf("expected *Error got %#v", err)
+			}
+			if providerErr.Provider != ElectricityMaps || providerErr.Location != "IN-KA" {
+				t.Errorf("expected provider and location got %q %q", providerErr.Provider, providerErr.Location)
+			}
+			if providerErr.Category != tc.category {
+				t.Errorf("expected category %q got %q", tc.category, providerErr.Category)
+			}
+			if providerErr.Attempts != tc.attempts {
+				t.Errorf("expected %d attempts got %d", tc.attempts, providerErr.Attempts)
+			}
+			if tc.statusCode != http.StatusOK {
+				if providerErr.StatusCode != tc.statusCode {
+					t.Errorf("expected status %d got %d", tc.statusCode, providerErr.StatusCode)
+				}
+				if providerErr.Body != tc.body {
+					t.Errorf("expected body %q got %q", tc.body, providerErr.Body)
+				}
+				if !errors.Is(err, ErrReceivedNon200Status) {
+					t.Errorf("expected %v got %v", ErrReceivedNon200Status, err)
+				}
+			}
+		})
+	}
+}
+
+func Test_Error_Message(t *testing.T) {
+	err := &Error{
+		Provider:   WattTime,
+		Location:   "CAISO_NORTH",
+		Category:   ErrorCategoryUpstream,
+		StatusCode: http.StatusBadGateway,
+		Body:       "bad gateway",
+		Attempts:   3,
+		Err:        ErrReceivedNon200Status,
+	}
+
+	expected := `WattTime location "CAISO_NORTH" after 3 attempts: 502 Bad Gateway - bad gateway: received non-200 status`
+	if err.Error() != expected {
+		t.Errorf("expected %q got %q", expected, err.Error())
+	}
+}
+
+func Test_Error_InvalidLocation(t *testing.T) {
+	p, err := NewEmber()
+	if err != nil {
+		t.Fatalf("Could not make provider: %s", err)
+	}
+
+	_, err = p.GetCarbonIntensity(context.Background(), "AAA")
+
+	var providerErr *Error
+	if !errors.As(err, &providerErr) {
+		t.Fatalf("expected *Error got %#v", err)
+	}
+	if providerErr.Category != ErrorCategoryNotFound {
+		t.Errorf("expected category %q got %q", ErrorCategoryNotFound, providerErr.Category)
+	}
+	if !errors.Is(err, ErrInvalidLocation) {
+		t.Errorf("expected %v got %v", ErrInvalidLocation, err)
+	}
+}
@@ -0,0 +1,244 @@
+package provider
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	fileLocationColumn      = "location"
+	fileValidFromColumn     = "valid_from"
+	fileValidToColumn       = "valid_to"
+	fileValueColumn         = "value"
+	fileUnitsColumn         = "units"
+	fileEmissionsTypeColumn = "emissions_type"
+	fileMetricTypeColumn    = "metric_type"
+	fileIsEstimatedColumn   = "is_estimated"
+)
+
+type FileClient struct {
+	path   string
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	data    []CarbonIntensity
+	modTime time.Time
+	size    int64
+}
+
+type FileConfig struct {
+	// Path to a CSV or JSON file with the time series. The format is based
+	// on the file extension.
+	Path   string
+	Logger *slog.Logger
+}
+
+func NewFile(config FileConfig) (Interface, error) {
+	if config.Path == "" {
+		return nil, fmt.Errorf("path must be set")
+	}
+
+	c := &FileClient{
+		path:   config.Path,
+		logger: newLogger(config.Logger, File),
+	}
+
+	// Load the file so errors are returned early.
+	_, err := c.getData()
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// GetCarbonIntensity returns the values for the location that are valid at
+// the current time. The file is reloaded if it has changed.
+func (f *FileClient) GetCarbonIntensity(ctx context.Context, location string) ([]CarbonIntensity, error) {
+	return callProvider(ctx, f.logger, File, location, f.getCarbonIntensity)
+}
+
+func (f *FileClient) getCarbonIntensity(ctx context.Context, location string) ([]CarbonIntensity, error) {
+	data, err := f.getData()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	var locationFound bool
+	var result []CarbonIntensity
+
+	for _, point := range data {
+		if !strings.EqualFold(point.Location, location) {
+			continue
+		}
+		locationFound = true
+
+		if !now.Before(point.ValidFrom) && now.Before(point.ValidTo) {
+			result = append(result, point)
+		}
+	}
+	if !locationFound {
+		return nil, ErrInvalidLocation
+	}
+	if len(result) == 0 {
+		return nil, ErrNoResponse
+	}
+
+	return result, nil
+}
+
+// getData returns the data from the file. It is only read again if the
+// modification time or size of the file has changed.
+func (f *FileClient) getData() ([]CarbonIntensity, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return nil, err
+	}
+	if f.data != nil && info.ModTime().Equal(f.modTime) && info.Size() == f.size {
+		return f.data, nil
+	}
+
+	file, err := os.Open(f.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var data []CarbonIntensity
+
+	switch strings.ToLower(filepath.Ext(f.path)) {
+	case ".csv":
+		data, err = parseCSVFile(file)
+	case ".json":
+		data, err = parseJSONFile(file)
+	default:
+		return nil, fmt.Errorf("file %s must have a .csv or .json extension", f.path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", f.path, err)
+	}
+
+	f.data = data
+	f.modTime = info.ModTime()
+	f.size = info.Size()
+
+	f.logger.Debug("loaded file", "path", f.path, "values", len(data))
+
+	return data, nil
+}
+
+func parseCSVFile(r io.Reader) ([]CarbonIntensity, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return []CarbonIntensity{}, nil
+	}
+
+	columns := map[string]int{}
+	for i, name := range rows[0] {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, name := range []string{fileLocationColumn, fileValidFromColumn, fileValidToColumn, fileValueColumn} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("column %#q not found", name)
+		}
+	}
+
+	// Optional columns return an empty string if they are not present.
+	get := func(row []string, name string) string {
+		i, ok := columns[name]
+		if !ok {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	result := make([]CarbonIntensity, 0, len(rows)-1)
+
+	for _, row := range rows[1:] {
+		validFrom, err := time.Parse(time.RFC3339, get(row, fileValidFromColumn))
+		if err != nil {
+			return nil, err
+		}
+		validTo, err := time.Parse(time.RFC3339, get(row, fileValidToColumn))
+		if err != nil {
+			return nil, err
+		}
+		value, err := strconv.ParseFloat(get(row, fileValueColumn), 64)
+		if err != nil {
+			return nil, err
+		}
+
+		var isEstimated bool
+		if raw := get(row, fileIsEstimatedColumn); raw != "" {
+			isEstimated, err = strconv.ParseBool(raw)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		result = append(result, withFileDefaults(CarbonIntensity{
+			EmissionsType: get(row, fileEmissionsTypeColumn),
+			MetricType:    get(row, fileMetricTypeColumn),
+			Location:      get(row, fileLocationColumn),
+			Units:         get(row, fileUnitsColumn),
+			ValidFrom:     validFrom,
+			ValidTo:       validTo,
+			Value:         value,
+			IsEstimated:   isEstimated,
+		}))
+	}
+
+	return result, nil
+}
+
+func parseJSONFile(r io.Reader) ([]CarbonIntensity, error) {
+	var data []CarbonIntensity
+
+	err := json.NewDecoder(r).Decode(&data)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, point := range data {
+		if point.Location == "" {
+			return nil, fmt.Errorf("location must be set")
+		}
+		data[i] = withFileDefaults(point)
+	}
+
+	return data, nil
+}
+
+// withFileDefaults sets the provider and the defaults for optional fields.
+func withFileDefaults(point CarbonIntensity) CarbonIntensity {
+	point.Provider = File
+
+	if point.EmissionsType == "" {
+		point.EmissionsType = AverageEmissionsType
+	}
+	if point.MetricType == "" {
+		point.MetricType = AbsoluteMetricType
+	}
+	if point.Units == "" {
+		point.Units = GramsCO2EPerkWh
+	}
+
+	return point
+}
@@ -0,0 +1,130 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var MockFileCSV = `location,valid_from,valid_to,value,units,emissions_type
+DE-PPA,%s,%s,%g,gCO2e per kWh,average
+DE-PPA,%s,%s,999,gCO2e per kWh,average
+`
+
+var MockFileJSON = `[
+	{
+		"location": "DE-PPA",
+		"valid_from": "%s",
+		"valid_to": "%s",
+		"value": %g,
+		"emissions_type": "marginal",
+		"is_estimated": true
+	}
+]`
+
+func Test_File_SimpleRequest(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Hour)
+	validFrom := now.Format(time.RFC3339)
+	validTo := now.Add(time.Hour).Format(time.RFC3339)
+	nextValidTo := now.Add(2 * time.Hour).Format(time.RFC3339)
+
+	tests := []struct {
+		name     string
+		fileName string
+		content  func(value float64) string
+		expected func(value float64) []CarbonIntensity
+	}{
+		{
+			name:     "csv",
+			fileName: "data.csv",
+			content: func(value float64) string {
+				return fmt.Sprintf(MockFileCSV, validFrom, validTo, value, validTo, nextValidTo)
+			},
+			expected: func(value float64) []CarbonIntensity {
+				return []CarbonIntensity{
+					{
+						EmissionsType: "average",
+						MetricType:    "absolute",
+						Provider:      "File",
+						Location:      "DE-PPA",
+						Units:         "gCO2e per kWh",
+						ValidFrom:     now,
+						ValidTo:       now.Add(time.Hour),
+						Value:         value,
+						IsEstimated:   false,
+					},
+				}
+			},
+		},
+		{
+			name:     "json",
+			fileName: "data.json",
+			content: func(value float64) string {
+				return fmt.Sprintf(MockFileJSON, validFrom, validTo, value)
+			},
+			expected: func(value float64) []CarbonIntensity {
+				return []CarbonIntensity{
+					{
+						EmissionsType: "marginal",
+						MetricType:    "absolute",
+						Provider:      "File",
+						Location:      "DE-PPA",
+						Units:         "gCO2e per kWh",
+						ValidFrom:     now,
+						ValidTo:       now.Add(time.Hour),
+						Value:         value,
+						IsEstimated:   true,
+					},
+				}
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tc.fileName)
+			err := os.WriteFile(path, []byte(tc.content(120)), 0644)
+			if err != nil {
+				t.Fatalf("could not write file: %s", err)
+			}
+
+			f, err := NewFile(FileConfig{Path: path})
+			if err != nil {
+				t.Fatalf("Could not make provider: %s", err)
+			}
+
+			res, err := f.GetCarbonIntensity(context.Background(), "de-ppa")
+			if err != nil {
+				t.Fatalf("got error on GetCarbonIntensity: %s", err)
+			}
+			if !reflect.DeepEqual(tc.expected(120), res) {
+				t.Errorf("want matching \n %s", cmp.Diff(res, tc.expected(120)))
+			}
+
+			// Update the file and check the new value is returned.
+			err = os.WriteFile(path, []byte(tc.content(80.5)), 0644)
+			if err != nil {
+				t.Fatalf("could not write file: %s", err)
+			}
+			later := time.Now().Add(time.Minute)
+			err = os.Chtimes(path, later, later)
+			if err != nil {
+				t.Fatalf("could not update file: %s", err)
+			}
+
+			res, err = f.GetCarbonIntensity(context.Background(), "DE-PPA")
+			if err != nil {
+				t.Fatalf("got error on GetCarbonIntensity: %s", err)
+			}
+			if !reflect.DeepEqual(tc.expected(80.5), res) {
+				t.Errorf("want matching \n %s", cmp.Diff(res, tc.expected(80.5)))
+			}
+		})
+	}
+}
@@ -0,0 +1,257 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+)
+
+// RetryConfig configures how requests to provider APIs are retried. Requests
+// are retried for network errors and for 429, 500, 502, 503 and 504 responses.
+type RetryConfig struct {
+	// MaxAttempts is the maximum number of attempts including the first.
+	// Defaults to 3. Set to 1 to disable retries.
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry. It is doubled for
+	// each retry and jitter is added. Defaults to 500 milliseconds.
+	InitialBackoff time.Duration
+	// MaxBackoff is the maximum wait between attempts. If a Retry-After
+	// header asks for a longer wait the request is not retried. Defaults to
+	// 10 seconds.
+	MaxBackoff time.Duration
+	// AttemptTimeout is optional and is the deadline for each attempt. The
+	// timeout of the HTTP client also applies to each attempt.
+	AttemptTimeout time.Duration
+}
+
+// httpClient sends requests to provider APIs with retries and rate limiting.
+// It is embedded in the clients for providers that make requests.
+type httpClient struct {
+	base    *http.Client
+	retry   RetryConfig
+	limiter *rateLimiter
+	logger  *slog.Logger
+	calls   coalescer
+}
+
+func newHTTPClient(client *http.Client, retry RetryConfig, rateLimit RateLimitConfig, logger *slog.Logger) *httpClient {
+	if retry.MaxAttempts == 0 {
+		retry.MaxAttempts = 3
+	}
+	if retry.InitialBackoff == 0 {
+		retry.InitialBackoff = 500 * time.Millisecond
+	}
+	if retry.MaxBackoff == 0 {
+		retry.MaxBackoff = 10 * time.Second
+	}
+
+	return &httpClient{
+		base:    client,
+		retry:   retry,
+		limiter: newRateLimiter(rateLimit),
+		logger:  logger,
+	}
+}
+
+// RemainingQuota returns the number of requests remaining today if a daily
+// quota is configured.
+func (c *httpClient) RemainingQuota() (int, bool) {
+	return c.limiter.RemainingQuota()
+}
+
+// coalesce calls get for the location unless a call for the same location is
+// in progress. Concurrent callers share the request and its result.
+func (c *httpClient) coalesce(ctx context.Context, provider, location string,
+	get func(context.Context, string) ([]CarbonIntensity, error)) ([]CarbonIntensity, error) {
+	result, err := c.calls.do(ctx, location, func(ctx context.Context) ([]CarbonIntensity, error) {
+		return callProvider(ctx, c.logger, provider, location, get)
+	})
+	if err != nil {
+		return nil, newError(provider, location, err)
+	}
+
+	return result, nil
+}
+
+// do sends the request and retries transient failures. If all attempts fail
+// the number of attempts is included in the error. Each attempt counts
+// towards the rate limit as upstream quotas charge for every request. If a
+// retry would exceed the limit the error from the last attempt is returned
+// instead. Requests must not have a body.
+func (c *httpClient) do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = c.retry.InitialBackoff
+	b.MaxInterval = c.retry.MaxBackoff
+	b.MaxElapsedTime = 0
+	b.Reset()
+
+	var lastErr error
+
+	for attempt := 1; ; attempt++ {
+		if c.limiter != nil {
+			err := c.limiter.allow(ctx)
+			if err != nil {
+				if lastErr != nil {
+					return nil, attemptsError(attempt-1, lastErr)
+				}
+				return nil, err
+			}
+		}
+
+		start := time.Now()
+		resp, err := c.doAttempt(req)
+		lastAttempt := attempt >= c.retry.MaxAttempts
+
+		if err != nil {
+			c.logger.DebugContext(ctx, "request failed",
+				"method", req.Method,
+				"url", logURL(req.URL),
+				"attempt", attempt,
+				"latency", time.Since(start),
+				"error", err)
+		} else {
+			c.logger.DebugContext(ctx, "request",
+				"method", req.Method,
+				"url", logURL(req.URL),
+				"attempt", attempt,
+				"latency", time.Since(start),
+				"status", resp.StatusCode)
+		}
+
+		if err != nil {
+			if ctx.Err() != nil || lastAttempt {
+				return nil, attemptsError(attempt, err)
+			}
+		} else if !isRetryableStatus(resp.StatusCode) {
+			return resp, nil
+		}
+
+		wait := b.NextBackOff()
+		if err == nil {
+			retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"))
+			if lastAttempt || (ok && retryAfter > c.retry.MaxBackoff) {
+				if attempt == 1 {
+					// Only a single attempt was made so return the response
+					// so the provider can handle it.
+					return resp, nil
+				}
+				defer resp.Body.Close()
+				return nil, attemptsError(attempt, errBadStatus(resp))
+			}
+			if ok && retryAfter > wait {
+				wait = retryAfter
+			}
+
+			// Reading the body for the error lets the connection be reused.
+			lastErr = errBadStatus(resp)
+			resp.Body.Close()
+		} else {
+			lastErr = err
+		}
+
+		c.logger.DebugContext(ctx, "retrying request",
+			"url", logURL(req.URL),
+			"attempt", attempt,
+			"wait", wait)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, attemptsError(attempt, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *httpClient) doAttempt(req *http.Request) (*http.Response, error) {
+	if c.retry.AttemptTimeout == 0 {
+		return c.base.Do(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), c.retry.AttemptTimeout)
+
+	resp, err := c.base.Do(req.Clone(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// The context is cancelled once the body has been read.
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+
+	return resp, nil
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+
+	return err
+}
+
+// attemptsError sets the number of attempts in the *Error in err. If err does
+// not contain an *Error one is created.
+func attemptsError(attempts int, err error) error {
+	if attempts == 1 {
+		return err
+	}
+
+	var e *Error
+	if !errors.As(err, &e) {
+		e = &Error{Err: err}
+		err = e
+	}
+	e.Attempts = attempts
+
+	return err
+}
+
+func isRetryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+// parseRetryAfter parses a Retry-After header which is either a number of
+// seconds or an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,168 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_HTTPClient_Retry(t *testing.T) {
+	var requests int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprintln(w, MockCarbonIntensityOrgUKResponse)
+	}))
+	defer ts.Close()
+
+	c := CarbonIntensityUKConfig{
+		APIURL: ts.URL,
+		Retry: RetryConfig{
+			InitialBackoff: time.Millisecond,
+		},
+	}
+	a, err := NewCarbonIntensityUK(c)
+	if err != nil {
+		t.Fatalf("Could not make provider: %s", err)
+	}
+
+	res, err := a.GetCarbonIntensity(context.Background(), "UK")
+	if err != nil {
+		t.Fatalf("got error on GetCarbonIntensity: %s", err)
+	}
+	if len(res) != 1 || res[0].Value != 190 {
+		t.Errorf("unexpected result %v", res)
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("expected 2 requests got %d", n)
+	}
+}
+
+func Test_HTTPClient_GiveUp(t *testing.T) {
+	var requests int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintln(w, "maintenance")
+	}))
+	defer ts.Close()
+
+	c := CarbonIntensityUKConfig{
+		APIURL: ts.URL,
+		Retry: RetryConfig{
+			MaxAttempts:    4,
+			InitialBackoff: time.Millisecond,
+		},
+	}
+	a, err := NewCarbonIntensityUK(c)
+	if err != nil {
+		t.Fatalf("Could not make provider: %s", err)
+	}
+
+	_, err = a.GetCarbonIntensity(context.Background(), "UK")
+	if !errors.Is(err, ErrReceivedNon200Status) {
+		t.Fatalf("expected %v got %v", ErrReceivedNon200Status, err)
+	}
+	if !strings.Contains(err.Error(), "after 4 attempts") || !strings.Contains(err.Error(), "maintenance") {
+		t.Errorf("expected attempts and body in error got %q", err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 4 {
+		t.Errorf("expected 4 requests got %d", n)
+	}
+}
+
+func Test_HTTPClient_RetryAfter(t *testing.T) {
+	var requests int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		fmt.Fprintln(w, MockCarbonIntensityOrgUKResponse)
+	}))
+	defer ts.Close()
+
+	c := CarbonIntensityUKConfig{
+		APIURL: ts.URL,
+		Retry: RetryConfig{
+			InitialBackoff: time.Millisecond,
+		},
+	}
+	a, err := NewCarbonIntensityUK(c)
+	if err != nil {
+		t.Fatalf("Could not make provider: %s", err)
+	}
+
+	start := time.Now()
+	_, err = a.GetCarbonIntensity(context.Background(), "UK")
+	if err != nil {
+		t.Fatalf("got error on GetCarbonIntensity: %s", err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("expected retry after 1s got %s", elapsed)
+	}
+
+	// Retry-After longer than the max backoff is not retried.
+	atomic.StoreInt32(&requests, 0)
+	c.Retry.MaxBackoff = 10 * time.Millisecond
+	a, err = NewCarbonIntensityUK(c)
+	if err != nil {
+		t.Fatalf("Could not make provider: %s", err)
+	}
+
+	_, err = a.GetCarbonIntensity(context.Background(), "UK")
+	if !errors.Is(err, ErrReceivedNon200Status) {
+		t.Errorf("expected %v got %v", ErrReceivedNon200Status, err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("expected 1 request got %d", n)
+	}
+}
+
+func Test_HTTPClient_AttemptTimeout(t *testing.T) {
+	var requests int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			select {
+			case <-time.After(time.Second):
+			case <-r.Context().Done():
+			}
+			return
+		}
+		fmt.Fprintln(w, MockCarbonIntensityOrgUKResponse)
+	}))
+	defer ts.Close()
+
+	c := CarbonIntensityUKConfig{
+		APIURL: ts.URL,
+		Retry: RetryConfig{
+			InitialBackoff: time.Millisecond,
+			AttemptTimeout: 50 * time.Millisecond,
+		},
+	}
+	a, err := NewCarbonIntensityUK(c)
+	if err != nil {
+		t.Fatalf("Could not make provider: %s", err)
+	}
+
+	_, err = a.GetCarbonIntensity(context.Background(), "UK")
+	if err != nil {
+		t.Fatalf("got error on GetCarbonIntensity: %s", err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("expected 2 requests got %d", n)
+	}
+}
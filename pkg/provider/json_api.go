@@ -0,0 +1,361 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JSONAPIClient is a provider for JSON APIs that is configured declaratively
+// rather than with Go code.
+type JSONAPIClient struct {
+	*httpClient
+
+	name          string
+	urlTemplate   string
+	headers       map[string]string
+	queryParams   map[string]string
+	mapping       JSONAPIMapping
+	emissionsType string
+	metricType    string
+	units         string
+	timeLayout    string
+	duration      time.Duration
+}
+
+type JSONAPIConfig struct {
+	Client    *http.Client
+	Retry     RetryConfig
+	RateLimit RateLimitConfig
+	Logger    *slog.Logger
+	// Name is used as the provider in results. Defaults to JSONAPI.
+	Name string
+	// URLTemplate is the URL to call. The {location} placeholder is replaced
+	// with the location escaped for the path or query where it appears e.g.
+	// https://example.com/intensity?zone={location}
+	URLTemplate string
+	// Headers are added to each request e.g. for authentication.
+	Headers map[string]string
+	// QueryParams are added to each request e.g. for an API key.
+	QueryParams map[string]string
+	// Mapping contains the paths to the fields in the response.
+	Mapping JSONAPIMapping
+	// EmissionsType defaults to average.
+	EmissionsType string
+	// MetricType defaults to absolute.
+	MetricType string
+	// Units are used if there is no units mapping. Defaults to gCO2e per kWh.
+	Units string
+	// TimeLayout is used to parse timestamps. Defaults to RFC 3339. The
+	// value unix can be used for timestamps in seconds since the epoch.
+	TimeLayout string
+	// Duration is used to calculate the valid to time if there is no valid
+	// to mapping. Defaults to 1 hour.
+	Duration time.Duration
+}
+
+// JSONAPIMapping contains the paths to fields in a JSON response. Paths use
+// a subset of JSONPath e.g. $.data[0].intensity.actual where keys are
+// separated by dots and array elements are selected by index. Negative
+// indexes select from the end of the array so [-1] is the last element.
+type JSONAPIMapping struct {
+	// Data is the path to an array of data points or a single data point.
+	// The other paths are relative to each data point. Defaults to the root.
+	Data string
+	// Value is the path to the carbon intensity value. Required.
+	Value string
+	// ValidFrom is the path to the start of the time period. Required.
+	ValidFrom string
+	// ValidTo is the path to the end of the time period. Optional.
+	ValidTo string
+	// Units is the path to the units. Optional.
+	Units string
+	// IsEstimated is the path to whether the value is estimated. Optional.
+	IsEstimated string
+}
+
+func NewJSONAPI(config JSONAPIConfig) (Interface, error) {
+	if config.URLTemplate == "" {
+		return nil, fmt.Errorf("url template must be set")
+	}
+	if config.Mapping.Value == "" || config.Mapping.ValidFrom == "" {
+		return nil, fmt.Errorf("value and valid from mappings must be set")
+	}
+
+	if config.Client == nil {
+		config.Client = &http.Client{
+			Timeout: 5 * time.Second,
+		}
+	}
+	if config.Name == "" {
+		config.Name = JSONAPI
+	}
+	if config.EmissionsType == "" {
+		config.EmissionsType = AverageEmissionsType
+	}
+	if config.MetricType == "" {
+		config.MetricType = AbsoluteMetricType
+	}
+	if config.Units == "" {
+		config.Units = GramsCO2EPerkWh
+	}
+	if config.TimeLayout == "" {
+		config.TimeLayout = time.RFC3339
+	}
+	if config.Duration == 0 {
+		config.Duration = time.Hour
+	}
+
+	c := &JSONAPIClient{
+		httpClient:    newHTTPClient(config.Client, config.Retry, config.RateLimit, newLogger(config.Logger, config.Name)),
+		name:          config.Name,
+		urlTemplate:   config.URLTemplate,
+		headers:       config.Headers,
+		queryParams:   config.QueryParams,
+		mapping:       config.Mapping,
+		emissionsType: config.EmissionsType,
+		metricType:    config.MetricType,
+		units:         config.Units,
+		timeLayout:    config.TimeLayout,
+		duration:      config.Duration,
+	}
+
+	return c, nil
+}
+
+func (j *JSONAPIClient) GetCarbonIntensity(ctx context.Context, location string) ([]CarbonIntensity, error) {
+	return j.coalesce(ctx, j.name, location, j.getCarbonIntensity)
+}
+
+func (j *JSONAPIClient) getCarbonIntensity(ctx context.Context, location string) ([]CarbonIntensity, error) {
+	requestURL := expandURLTemplate(j.urlTemplate, location)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(j.queryParams) > 0 {
+		query := req.URL.Query()
+		for key, value := range j.queryParams {
+			query.Set(key, value)
+		}
+		req.URL.RawQuery = query.Encode()
+	}
+	for key, value := range j.headers {
+		req.Header.Set(key, value)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := j.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errBadStatus(resp)
+	}
+
+	var respObj interface{}
+	err = json.NewDecoder(resp.Body).Decode(&respObj)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := lookupJSONPath(respObj, j.mapping.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	dataPoints, ok := data.([]interface{})
+	if !ok {
+		dataPoints = []interface{}{data}
+	}
+	if len(dataPoints) == 0 {
+		return nil, ErrNoResponse
+	}
+
+	result := make([]CarbonIntensity, 0, len(dataPoints))
+
+	for _, dataPoint := range dataPoints {
+		point, err := j.toCarbonIntensity(location, dataPoint)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *point)
+	}
+
+	return result, nil
+}
+
+func (j *JSONAPIClient) toCarbonIntensity(location string, dataPoint interface{}) (*CarbonIntensity, error) {
+	rawValue, err := lookupJSONPath(dataPoint, j.mapping.Value)
+	if err != nil {
+		return nil, err
+	}
+	value, err := jsonToFloat(rawValue)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse value: %w", err)
+	}
+
+	rawValidFrom, err := lookupJSONPath(dataPoint, j.mapping.ValidFrom)
+	if err != nil {
+		return nil, err
+	}
+	validFrom, err := j.parseTime(rawValidFrom)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse valid from: %w", err)
+	}
+
+	validTo := validFrom.Add(j.duration)
+	if j.mapping.ValidTo != "" {
+		rawValidTo, err := lookupJSONPath(dataPoint, j.mapping.ValidTo)
+		if err != nil {
+			return nil, err
+		}
+		validTo, err = j.parseTime(rawValidTo)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse valid to: %w", err)
+		}
+	}
+
+	units := j.units
+	if j.mapping.Units != "" {
+		rawUnits, err := lookupJSONPath(dataPoint, j.mapping.Units)
+		if err != nil {
+			return nil, err
+		}
+		units = fmt.Sprint(rawUnits)
+	}
+
+	var isEstimated bool
+	if j.mapping.IsEstimated != "" {
+		rawIsEstimated, err := lookupJSONPath(dataPoint, j.mapping.IsEstimated)
+		if err != nil {
+			return nil, err
+		}
+		isEstimated, err = strconv.ParseBool(fmt.Sprint(rawIsEstimated))
+		if err != nil {
+			return nil, fmt.Errorf("could not parse is estimated: %w", err)
+		}
+	}
+
+	return &CarbonIntensity{
+		EmissionsType: j.emissionsType,
+		MetricType:    j.metricType,
+		Provider:      j.name,
+		Location:      location,
+		Units:         units,
+		ValidFrom:     validFrom,
+		ValidTo:       validTo,
+		Value:         value,
+		IsEstimated:   isEstimated,
+	}, nil
+}
+
+func (j *JSONAPIClient) parseTime(raw interface{}) (time.Time, error) {
+	if j.timeLayout == "unix" {
+		seconds, err := jsonToFloat(raw)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Unix(int64(seconds), 0).UTC(), nil
+	}
+
+	value, ok := raw.(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("expected string got %T", raw)
+	}
+
+	return time.Parse(j.timeLayout, value)
+}
+
+// lookupJSONPath returns the value at the path in data decoded from JSON.
+// An empty path or $ returns data.
+func lookupJSONPath(data interface{}, path string) (interface{}, error) {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	if path == "" {
+		return data, nil
+	}
+
+	current := data
+
+	for _, part := range strings.Split(path, ".") {
+		key := part
+		var indexes []string
+
+		if i := strings.Index(part, "["); i >= 0 {
+			key = part[:i]
+			for _, index := range strings.Split(part[i+1:], "[") {
+				if !strings.HasSuffix(index, "]") {
+					return nil, fmt.Errorf("invalid path %q", path)
+				}
+				indexes = append(indexes, strings.TrimSuffix(index, "]"))
+			}
+		}
+
+		if key != "" {
+			object, ok := current.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("path %q: expected object for %q", path, key)
+			}
+			current, ok = object[key]
+			if !ok {
+				return nil, fmt.Errorf("path %q: key %q not found", path, key)
+			}
+		}
+
+		for _, rawIndex := range indexes {
+			array, ok := current.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("path %q: expected array for %q", path, part)
+			}
+			index, err := strconv.Atoi(rawIndex)
+			if err != nil {
+				return nil, fmt.Errorf("path %q: invalid index %q", path, rawIndex)
+			}
+			if index < 0 {
+				index += len(array)
+			}
+			if index < 0 || index >= len(array) {
+				return nil, fmt.Errorf("path %q: index %s out of range", path, rawIndex)
+			}
+			current = array[index]
+		}
+	}
+
+	return current, nil
+}
+
+// jsonToFloat converts a JSON number or a string containing a number.
+func jsonToFloat(raw interface{}) (float64, error) {
+	switch value := raw.(type) {
+	case float64:
+		return value, nil
+	case string:
+		return strconv.ParseFloat(value, 64)
+	default:
+		return 0, fmt.Errorf("expected number got %T", raw)
+	}
+}
+
+// expandURLTemplate replaces the {location} placeholder in the template. The
+// location is escaped as a path segment before the query and as a query
+// value after it.
+func expandURLTemplate(template, location string) string {
+	path, query, hasQuery := strings.Cut(template, "?")
+
+	result := strings.ReplaceAll(path, "{location}", url.PathEscape(location))
+	if hasQuery {
+		result += "?" + strings.ReplaceAll(query, "{location}", url.QueryEscape(location))
+	}
+
+	return result
+}
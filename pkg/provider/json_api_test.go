@@ -0,0 +1,153 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_JSONAPI_SimpleRequest(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/regional/intensity/UK" {
+			t.Errorf("unknown path %#q", r.URL.Path)
+		}
+		if r.Header.Get("X-API-Key") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintln(w, MockCarbonIntensityOrgUKResponse)
+	}))
+	defer ts.Close()
+
+	// The CarbonIntensityUKClient response can be mapped with config.
+	c := JSONAPIConfig{
+		Name:        "Internal",
+		URLTemplate: ts.URL + "/regional/intensity/{location}",
+		Headers: map[string]string{
+			"X-API-Key": "secret",
+		},
+		Mapping: JSONAPIMapping{
+			Data:      "$.data",
+			Value:     "intensity.actual",
+			ValidFrom: "from",
+			ValidTo:   "to",
+		},
+		TimeLayout: "2006-01-02T15:04Z",
+	}
+	a, err := NewJSONAPI(c)
+	if err != nil {
+		t.Errorf("Could not make provider: %s", err)
+		return
+	}
+
+	res, err := a.GetCarbonIntensity(context.Background(), "UK")
+	if err != nil {
+		t.Fatalf("got error on GetCarbonIntensity: %s", err)
+	}
+
+	expected := []CarbonIntensity{
+		{
+			Provider:      "Internal",
+			EmissionsType: "average",
+			MetricType:    "absolute",
+			Location:      "UK",
+			Units:         "gCO2e per kWh",
+			ValidFrom:     time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			ValidTo:       time.Date(2020, 1, 1, 0, 30, 0, 0, time.UTC),
+			Value:         190,
+			IsEstimated:   false,
+		},
+	}
+	if !reflect.DeepEqual(expected, res) {
+		t.Errorf("want matching \n %s", cmp.Diff(res, expected))
+	}
+}
+
+func Test_JSONAPI_Mapping(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("zone") != "IN-KA" || r.URL.Query().Get("key") != "secret" {
+			t.Errorf("unexpected query %#q", r.URL.RawQuery)
+		}
+		fmt.Fprintln(w, MockElectricityMapResponse)
+	}))
+	defer ts.Close()
+
+	c := JSONAPIConfig{
+		URLTemplate: ts.URL + "/history?zone={location}",
+		QueryParams: map[string]string{
+			"key": "secret",
+		},
+		Mapping: JSONAPIMapping{
+			Data:        "history[-1]",
+			Value:       "carbonIntensity",
+			ValidFrom:   "datetime",
+			IsEstimated: "isEstimated",
+		},
+		Duration: 30 * time.Minute,
+	}
+	a, err := NewJSONAPI(c)
+	if err != nil {
+		t.Errorf("Could not make provider: %s", err)
+		return
+	}
+
+	res, err := a.GetCarbonIntensity(context.Background(), "IN-KA")
+	if err != nil {
+		t.Fatalf("got error on GetCarbonIntensity: %s", err)
+	}
+
+	expected := []CarbonIntensity{
+		{
+			Provider:      "JSONAPI",
+			EmissionsType: "average",
+			MetricType:    "absolute",
+			Location:      "IN-KA",
+			Units:         "gCO2e per kWh",
+			ValidFrom:     time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			ValidTo:       time.Date(2020, 1, 1, 0, 30, 0, 0, time.UTC),
+			Value:         312,
+			IsEstimated:   true,
+		},
+	}
+	if !reflect.DeepEqual(expected, res) {
+		t.Errorf("want matching \n %s", cmp.Diff(res, expected))
+	}
+}
+
+func Test_JSONAPI_EscapeLocation(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if path := r.URL.EscapedPath(); path != "/intensity/New%20York%2FNY" {
+			t.Errorf("unexpected path %#q", path)
+		}
+		if r.URL.RawQuery != "zone=New+York%2FNY" {
+			t.Errorf("unexpected query %#q", r.URL.RawQuery)
+		}
+		fmt.Fprintln(w, MockCarbonIntensityOrgUKResponse)
+	}))
+	defer ts.Close()
+
+	c := JSONAPIConfig{
+		URLTemplate: ts.URL + "/intensity/{location}?zone={location}",
+		Mapping: JSONAPIMapping{
+			Data:      "$.data",
+			Value:     "intensity.actual",
+			ValidFrom: "from",
+		},
+		TimeLayout: "2006-01-02T15:04Z",
+	}
+	a, err := NewJSONAPI(c)
+	if err != nil {
+		t.Fatalf("Could not make provider: %s", err)
+	}
+
+	_, err = a.GetCarbonIntensity(context.Background(), "New York/NY")
+	if err != nil {
+		t.Fatalf("got error on GetCarbonIntensity: %s", err)
+	}
+}
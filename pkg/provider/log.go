@@ -0,0 +1,78 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/url"
+	"time"
+)
+
+// newLogger returns the logger for a provider with the provider name added
+// to each record. Defaults to slog.Default.
+func newLogger(logger *slog.Logger, provider string) *slog.Logger {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return logger.With("provider", provider)
+}
+
+// callProvider calls get and logs the location, latency and outcome at debug
+// level. Errors are returned as an *Error for the provider and location.
+func callProvider(ctx context.Context, logger *slog.Logger, provider, location string,
+	get func(context.Context, string) ([]CarbonIntensity, error)) ([]CarbonIntensity, error) {
+	start := time.Now()
+
+	result, err := get(ctx, location)
+	if err != nil {
+		err = newError(provider, location, err)
+
+		attrs := []any{"location", location, "latency", time.Since(start)}
+		var e *Error
+		if errors.As(err, &e) {
+			if e.StatusCode != 0 {
+				attrs = append(attrs, "status", e.StatusCode)
+			}
+			attrs = append(attrs, "category", string(e.Category))
+		}
+		logger.DebugContext(ctx, "could not get carbon intensity", append(attrs, "error", err)...)
+
+		return nil, err
+	}
+
+	logger.DebugContext(ctx, "got carbon intensity",
+		"location", location,
+		"latency", time.Since(start),
+		"results", len(result))
+
+	return result, nil
+}
+
+// callProviderMulti calls get for multiple locations and logs the latency
+// and number of errors at debug level.
+func callProviderMulti(ctx context.Context, logger *slog.Logger, locations []string,
+	get func(context.Context, []string) (map[string][]CarbonIntensity, map[string]error)) (map[string][]CarbonIntensity, map[string]error) {
+	start := time.Now()
+
+	results, errs := get(ctx, locations)
+
+	logger.DebugContext(ctx, "got carbon intensity for locations",
+		"locations", len(locations),
+		"errors", len(errs),
+		"latency", time.Since(start))
+
+	return results, errs
+}
+
+// logURL returns the URL without the query or user info which may contain
+// credentials.
+func logURL(u *url.URL) string {
+	redacted := url.URL{
+		Scheme: u.Scheme,
+		Host:   u.Host,
+		Path:   u.Path,
+	}
+
+	return redacted.String()
+}
@@ -0,0 +1,68 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func Test_Logger(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, MockCarbonIntensityOrgUKResponse)
+	}))
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+	}))
+
+	c := CarbonIntensityUKConfig{
+		APIURL: ts.URL + "?secret=value",
+		Logger: logger,
+	}
+	a, err := NewCarbonIntensityUK(c)
+	if err != nil {
+		t.Fatalf("Could not make provider: %s", err)
+	}
+
+	_, err = a.GetCarbonIntensity(context.Background(), "UK")
+	if err != nil {
+		t.Fatalf("got error on GetCarbonIntensity: %s", err)
+	}
+
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		record := map[string]interface{}{}
+		err := json.Unmarshal([]byte(line), &record)
+		if err != nil {
+			t.Fatalf("could not parse log record %q: %s", line, err)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 log records got %d: %s", len(records), buf.String())
+	}
+
+	request := records[0]
+	if request["msg"] != "request" || request["provider"] != CarbonIntensityOrgUK || request["status"] != float64(http.StatusOK) {
+		t.Errorf("unexpected request record %v", request)
+	}
+	if _, ok := request["latency"]; !ok {
+		t.Errorf("expected latency in request record %v", request)
+	}
+	if strings.Contains(buf.String(), "secret") {
+		t.Errorf("expected query to be removed from url %v", request["url"])
+	}
+
+	result := records[1]
+	if result["msg"] != "got carbon intensity" || result["provider"] != CarbonIntensityOrgUK || result["location"] != "UK" {
+		t.Errorf("unexpected result record %v", result)
+	}
+}
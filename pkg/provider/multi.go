@@ -0,0 +1,100 @@
+package provider
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultParallelism is the number of locations requested at once by
+// GetCarbonIntensityMulti.
+const DefaultParallelism = 4
+
+// MultiInterface is implemented by providers that can get the data for
+// multiple locations with fewer requests than getting each location.
+type MultiInterface interface {
+	// GetCarbonIntensityMulti returns the results and errors keyed by
+	// location.
+	GetCarbonIntensityMulti(ctx context.Context, locations []string) (map[string][]CarbonIntensity, map[string]error)
+}
+
+// GetCarbonIntensityMulti gets the carbon intensity for each location and
+// returns the results and errors keyed by location. If the client implements
+// MultiInterface its batch requests are used. Otherwise up to parallelism
+// locations are requested at once. Parallelism defaults to
+// DefaultParallelism.
+func GetCarbonIntensityMulti(ctx context.Context, client Interface, locations []string, parallelism int) (map[string][]CarbonIntensity, map[string]error) {
+	locations = uniqueLocations(locations)
+
+	if multi, ok := client.(MultiInterface); ok {
+		return multi.GetCarbonIntensityMulti(ctx, locations)
+	}
+
+	return getEach(ctx, locations, parallelism, client.GetCarbonIntensity)
+}
+
+// GetCarbonIntensityQueryMulti runs the query for each location and returns
+// the results and errors keyed by location. The location of the query is
+// replaced by each location. Up to parallelism locations are requested at
+// once. Parallelism defaults to DefaultParallelism.
+func GetCarbonIntensityQueryMulti(ctx context.Context, client Interface, query Query, locations []string, parallelism int) (map[string][]CarbonIntensity, map[string]error) {
+	locations = uniqueLocations(locations)
+
+	return getEach(ctx, locations, parallelism, func(ctx context.Context, location string) ([]CarbonIntensity, error) {
+		query := query
+		query.Location = location
+		return GetCarbonIntensityQuery(ctx, client, query)
+	})
+}
+
+// getEach calls get for each location with up to parallelism calls at once.
+func getEach(ctx context.Context, locations []string, parallelism int,
+	get func(context.Context, string) ([]CarbonIntensity, error)) (map[string][]CarbonIntensity, map[string]error) {
+	if parallelism <= 0 {
+		parallelism = DefaultParallelism
+	}
+
+	results := make(map[string][]CarbonIntensity, len(locations))
+	errs := map[string]error{}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, parallelism)
+
+	for _, location := range locations {
+		wg.Add(1)
+		go func(location string) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			result, err := get(ctx, location)
+			<-sem
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs[location] = err
+				return
+			}
+			results[location] = result
+		}(location)
+	}
+	wg.Wait()
+
+	return results, errs
+}
+
+// uniqueLocations removes duplicate locations keeping the order.
+func uniqueLocations(locations []string) []string {
+	seen := make(map[string]bool, len(locations))
+	result := make([]string, 0, len(locations))
+
+	for _, location := range locations {
+		if seen[location] {
+			continue
+		}
+		seen[location] = true
+		result = append(result, location)
+	}
+
+	return result
+}
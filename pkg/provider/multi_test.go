@@ -0,0 +1,140 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_GetCarbonIntensityMulti(t *testing.T) {
+	var requests, inFlight, maxInFlight int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		location := strings.TrimPrefix(r.URL.Path, "/")
+		if location == "invalid" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `{"value": 100, "from": "2024-01-01T00:00:00Z", "location": %q}`, location)
+	}))
+	defer ts.Close()
+
+	c := JSONAPIConfig{
+		URLTemplate: ts.URL + "/{location}",
+		Mapping: JSONAPIMapping{
+			Value:     "$.value",
+			ValidFrom: "$.from",
+		},
+		Retry: RetryConfig{
+			MaxAttempts: 1,
+		},
+	}
+	p, err := NewJSONAPI(c)
+	if err != nil {
+		t.Fatalf("Could not make provider: %s", err)
+	}
+
+	locations := []string{"a", "b", "c", "d", "e", "invalid", "a"}
+
+	results, errs := GetCarbonIntensityMulti(context.Background(), p, locations, 2)
+
+	for _, location := range []string{"a", "b", "c", "d", "e"} {
+		if len(results[location]) != 1 || results[location][0].Location != location {
+			t.Errorf("unexpected result for %s %v", location, results[location])
+		}
+	}
+	if len(results) != 5 {
+		t.Errorf("expected 5 results got %d", len(results))
+	}
+
+	var providerErr *Error
+	if !errors.As(errs["invalid"], &providerErr) || providerErr.Category != ErrorCategoryNotFound {
+		t.Errorf("expected not found error got %v", errs["invalid"])
+	}
+	if len(errs) != 1 {
+		t.Errorf("expected 1 error got %d", len(errs))
+	}
+
+	// Duplicate locations are only requested once.
+	if n := atomic.LoadInt32(&requests); n != 6 {
+		t.Errorf("expected 6 requests got %d", n)
+	}
+	if n := atomic.LoadInt32(&maxInFlight); n > 2 {
+		t.Errorf("expected at most 2 concurrent requests got %d", n)
+	}
+}
+
+func Test_GetCarbonIntensityQueryMulti(t *testing.T) {
+	var inFlight, maxInFlight int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		location := strings.TrimPrefix(r.URL.Path, "/")
+		fmt.Fprintf(w, `{"value": 100, "from": "2024-01-01T00:00:00Z", "location": %q}`, location)
+	}))
+	defer ts.Close()
+
+	c := JSONAPIConfig{
+		URLTemplate: ts.URL + "/{location}",
+		Mapping: JSONAPIMapping{
+			Value:     "$.value",
+			ValidFrom: "$.from",
+		},
+	}
+	p, err := NewJSONAPI(c)
+	if err != nil {
+		t.Fatalf("Could not make provider: %s", err)
+	}
+
+	locations := []string{"a", "b", "c", "d", "e"}
+
+	query := Query{
+		EmissionsType: AverageEmissionsType,
+	}
+	results, errs := GetCarbonIntensityQueryMulti(context.Background(), p, query, locations, 2)
+
+	for _, location := range locations {
+		if len(results[location]) != 1 || results[location][0].Location != location {
+			t.Errorf("unexpected result for %s %v", location, results[location])
+		}
+	}
+	if len(errs) != 0 {
+		t.Errorf("expected no errors got %v", errs)
+	}
+	if n := atomic.LoadInt32(&maxInFlight); n > 2 {
+		t.Errorf("expected at most 2 concurrent requests got %d", n)
+	}
+
+	// Each location is filtered by the query.
+	query.EmissionsType = MarginalEmissionsType
+	_, errs = GetCarbonIntensityQueryMulti(context.Background(), p, query, locations, 2)
+	if len(errs) != len(locations) || !errors.Is(errs["a"], ErrNoMarginalIntensityPresent) {
+		t.Errorf("expected %v for each location got %v", ErrNoMarginalIntensityPresent, errs)
+	}
+}
@@ -0,0 +1,193 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// PluginPrefix is the prefix for the names of plugin executables e.g.
+	// grid-intensity-provider-example for the provider named example.
+	PluginPrefix = "grid-intensity-provider-"
+
+	// PluginProtocolVersion is the version of the JSON protocol used to
+	// communicate with plugins.
+	PluginProtocolVersion = 1
+
+	// Error codes plugins can return.
+	PluginErrInvalidLocation = "invalid_location"
+	PluginErrNoResponse      = "no_response"
+)
+
+// PluginClient is a provider implemented by an external executable. A
+// PluginRequest is written to its stdin as JSON and it must write a
+// PluginResponse to its stdout as JSON and exit.
+type PluginClient struct {
+	name    string
+	path    string
+	timeout time.Duration
+	logger  *slog.Logger
+}
+
+type PluginConfig struct {
+	// Name of the provider.
+	Name string
+	// Path to the plugin executable. Defaults to PluginPrefix followed by
+	// the name looked up on the PATH.
+	Path string
+	// Timeout for each call to the plugin. Defaults to 30 seconds.
+	Timeout time.Duration
+	Logger  *slog.Logger
+}
+
+type PluginRequest struct {
+	Version  int    `json:"version"`
+	Location string `json:"location"`
+}
+
+type PluginResponse struct {
+	Data []CarbonIntensity `json:"data"`
+	// Error is set if the request failed.
+	Error string `json:"error,omitempty"`
+	// ErrorCode is optional and is one of the PluginErr constants.
+	ErrorCode string `json:"error_code,omitempty"`
+}
+
+func NewPlugin(config PluginConfig) (Interface, error) {
+	if config.Name == "" {
+		return nil, fmt.Errorf("name must be set")
+	}
+	if config.Path == "" {
+		path, err := LookupPlugin(config.Name)
+		if err != nil {
+			return nil, err
+		}
+		config.Path = path
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 30 * time.Second
+	}
+
+	c := &PluginClient{
+		name:    config.Name,
+		path:    config.Path,
+		timeout: config.Timeout,
+		logger:  newLogger(config.Logger, config.Name),
+	}
+
+	return c, nil
+}
+
+// LookupPlugin returns the path of the plugin executable for the provider
+// name on the PATH. Names with path separators or .. are rejected so they
+// cannot resolve to executables outside the PATH.
+func LookupPlugin(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || strings.Contains(name, "..") {
+		return "", fmt.Errorf("invalid plugin name %q", name)
+	}
+
+	return exec.LookPath(PluginPrefix + name)
+}
+
+// FindPlugins returns the names of the plugins found on the PATH.
+func FindPlugins() []string {
+	found := map[string]bool{}
+
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		matches, err := filepath.Glob(filepath.Join(dir, PluginPrefix+"*"))
+		if err != nil {
+			continue
+		}
+		for _, match := range matches {
+			name := strings.TrimPrefix(filepath.Base(match), PluginPrefix)
+			name = strings.TrimSuffix(name, filepath.Ext(name))
+			if _, err := LookupPlugin(name); err == nil {
+				found[name] = true
+			}
+		}
+	}
+
+	result := make([]string, 0, len(found))
+	for name := range found {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+func (p *PluginClient) GetCarbonIntensity(ctx context.Context, location string) ([]CarbonIntensity, error) {
+	return callProvider(ctx, p.logger, p.name, location, p.getCarbonIntensity)
+}
+
+func (p *PluginClient) getCarbonIntensity(ctx context.Context, location string) ([]CarbonIntensity, error) {
+	request, err := json.Marshal(PluginRequest{
+		Version:  PluginProtocolVersion,
+		Location: location,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, p.path)
+	cmd.Stdin = bytes.NewReader(request)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	p.logger.DebugContext(ctx, "running plugin", "path", p.path, "location", location)
+
+	err = cmd.Run()
+	if err != nil {
+		return nil, fmt.Errorf("plugin failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	response := PluginResponse{}
+	err = json.Unmarshal(stdout.Bytes(), &response)
+	if err != nil {
+		return nil, fmt.Errorf("plugin returned invalid response: %w", err)
+	}
+
+	if response.Error != "" || response.ErrorCode != "" {
+		switch response.ErrorCode {
+		case PluginErrInvalidLocation:
+			return nil, fmt.Errorf("%s: %w", response.Error, ErrInvalidLocation)
+		case PluginErrNoResponse:
+			return nil, fmt.Errorf("%s: %w", response.Error, ErrNoResponse)
+		default:
+			if response.ErrorCode == "" {
+				return nil, errors.New(response.Error)
+			}
+			message := fmt.Sprintf("plugin returned error code %q", response.ErrorCode)
+			if response.Error != "" {
+				message += ": " + response.Error
+			}
+			return nil, errors.New(message)
+		}
+	}
+
+	for i := range response.Data {
+		if response.Data[i].Provider == "" {
+			response.Data[i].Provider = p.name
+		}
+		if response.Data[i].Location == "" {
+			response.Data[i].Location = location
+		}
+	}
+
+	return response.Data, nil
+}
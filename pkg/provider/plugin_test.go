@@ -0,0 +1,99 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// MockPluginScript is a fake plugin that supports the location UK.
+var MockPluginScript = `#!/bin/sh
+read request
+case "$request" in
+*'"location":"UK"'*)
+	echo '{"data":[{"emissions_type":"average","metric_type":"absolute","units":"gCO2e per kWh","valid_from":"2020-01-01T00:00:00Z","valid_to":"2020-01-01T00:30:00Z","value":190,"is_estimated":true}]}'
+	;;
+*'"location":"quota"'*)
+	echo '{"error_code":"quota_exceeded"}'
+	;;
+*)
+	echo '{"error":"only UK is supported","error_code":"invalid_location"}'
+	;;
+esac
+`
+
+func Test_Plugin_SimpleRequest(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake plugin is a shell script")
+	}
+
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, PluginPrefix+"fake"), []byte(MockPluginScript), 0755)
+	if err != nil {
+		t.Fatalf("could not write plugin: %s", err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	if plugins := FindPlugins(); !reflect.DeepEqual(plugins, []string{"fake"}) {
+		t.Errorf("expected plugin fake got %v", plugins)
+	}
+
+	p, err := NewPlugin(PluginConfig{Name: "fake"})
+	if err != nil {
+		t.Fatalf("Could not make provider: %s", err)
+	}
+
+	res, err := p.GetCarbonIntensity(context.Background(), "UK")
+	if err != nil {
+		t.Fatalf("got error on GetCarbonIntensity: %s", err)
+	}
+
+	expected := []CarbonIntensity{
+		{
+			EmissionsType: "average",
+			MetricType:    "absolute",
+			Provider:      "fake",
+			Location:      "UK",
+			Units:         "gCO2e per kWh",
+			ValidFrom:     time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			ValidTo:       time.Date(2020, 1, 1, 0, 30, 0, 0, time.UTC),
+			Value:         190,
+			IsEstimated:   true,
+		},
+	}
+	if !reflect.DeepEqual(expected, res) {
+		t.Errorf("want matching \n %s", cmp.Diff(res, expected))
+	}
+
+	_, err = p.GetCarbonIntensity(context.Background(), "FR")
+	if !errors.Is(err, ErrInvalidLocation) {
+		t.Errorf("expected %v got %v", ErrInvalidLocation, err)
+	}
+
+	// Unknown error codes are included in the error.
+	_, err = p.GetCarbonIntensity(context.Background(), "quota")
+	if err == nil || !strings.Contains(err.Error(), "quota_exceeded") {
+		t.Errorf("expected error with error code got %v", err)
+	}
+
+	_, err = NewPlugin(PluginConfig{Name: "missing"})
+	if err == nil {
+		t.Errorf("expected error for missing plugin")
+	}
+
+	// Names that could resolve outside the PATH are rejected.
+	for _, name := range []string{"../fake", "dir/fake", ".."} {
+		_, err = LookupPlugin(name)
+		if err == nil {
+			t.Errorf("expected error for plugin name %q", name)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PrometheusClient is a provider that evaluates a PromQL query against the
+// Prometheus HTTP API. This allows carbon intensity data that is already
+// collected in Prometheus to be reused.
+type PrometheusClient struct {
+	*httpClient
+
+	apiURL        string
+	name          string
+	query         string
+	headers       map[string]string
+	queryRange    time.Duration
+	step          time.Duration
+	emissionsType string
+	metricType    string
+	units         string
+}
+
+type PrometheusConfig struct {
+	Client    *http.Client
+	Retry     RetryConfig
+	RateLimit RateLimitConfig
+	Logger    *slog.Logger
+	// APIURL is the base URL of the Prometheus server e.g.
+	// http://localhost:9090
+	APIURL string
+	// Name is used as the provider in results. Defaults to Prometheus.
+	Name string
+	// Query is the PromQL query to evaluate. The {location} placeholder is
+	// replaced with the location escaped for use in a label matcher e.g.
+	// grid_intensity_carbon_average{location="{location}"}
+	Query string
+	// Headers are added to each request e.g. for authentication.
+	Headers map[string]string
+	// Range is optional. If set a range query is used to return the values
+	// for this period up to the current time. Otherwise an instant query is
+	// used to return the current value.
+	Range time.Duration
+	// Step is the resolution of range queries and the period each value is
+	// valid for. Defaults to 5 minutes.
+	Step time.Duration
+	// EmissionsType defaults to average.
+	EmissionsType string
+	// MetricType defaults to absolute.
+	MetricType string
+	// Units defaults to gCO2e per kWh.
+	Units string
+}
+
+type prometheusResponse struct {
+	Status    string         `json:"status"`
+	Data      prometheusData `json:"data"`
+	ErrorType string         `json:"errorType"`
+	Error     string         `json:"error"`
+}
+
+type prometheusData struct {
+	ResultType string          `json:"resultType"`
+	Result     json.RawMessage `json:"result"`
+}
+
+type prometheusSeries struct {
+	Metric map[string]string `json:"metric"`
+	Value  []interface{}     `json:"value"`
+	Values [][]interface{}   `json:"values"`
+}
+
+func NewPrometheus(config PrometheusConfig) (Interface, error) {
+	if config.APIURL == "" {
+		return nil, fmt.Errorf("api url must be set")
+	}
+	if config.Query == "" {
+		return nil, fmt.Errorf("query must be set")
+	}
+
+	if config.Client == nil {
+		config.Client = &http.Client{
+			Timeout: 5 * time.Second,
+		}
+	}
+	if config.Name == "" {
+		config.Name = Prometheus
+	}
+	if config.Step == 0 {
+		config.Step = 5 * time.Minute
+	}
+	if config.EmissionsType == "" {
+		config.EmissionsType = AverageEmissionsType
+	}
+	if config.MetricType == "" {
+		config.MetricType = AbsoluteMetricType
+	}
+	if config.Units == "" {
+		config.Units = GramsCO2EPerkWh
+	}
+
+	c := &PrometheusClient{
+		httpClient:    newHTTPClient(config.Client, config.Retry, config.RateLimit, newLogger(config.Logger, config.Name)),
+		apiURL:        strings.TrimSuffix(config.APIURL, "/"),
+		name:          config.Name,
+		query:         config.Query,
+		headers:       config.Headers,
+		queryRange:    config.Range,
+		step:          config.Step,
+		emissionsType: config.EmissionsType,
+		metricType:    config.MetricType,
+		units:         config.Units,
+	}
+
+	return c, nil
+}
+
+func (p *PrometheusClient) GetCarbonIntensity(ctx context.Context, location string) ([]CarbonIntensity, error) {
+	return p.coalesce(ctx, p.name, location, p.getCarbonIntensity)
+}
+
+func (p *PrometheusClient) getCarbonIntensity(ctx context.Context, location string) ([]CarbonIntensity, error) {
+	// Escape the location so it can't change the query.
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(location)
+	query := strings.ReplaceAll(p.query, "{location}", escaped)
+
+	now := time.Now().UTC()
+	params := url.Values{}
+	params.Set("query", query)
+
+	var requestURL string
+	if p.queryRange > 0 {
+		requestURL = p.apiURL + "/api/v1/query_range"
+		params.Set("start", formatPrometheusTime(now.Add(-p.queryRange)))
+		params.Set("end", formatPrometheusTime(now))
+		params.Set("step", strconv.FormatFloat(p.step.Seconds(), 'f', -1, 64))
+	} else {
+		requestURL = p.apiURL + "/api/v1/query"
+		params.Set("time", formatPrometheusTime(now))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range p.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := p.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respObj := prometheusResponse{}
+
+	// Prometheus returns errors in the same format as results so try to
+	// decode them for a better error message.
+	if resp.StatusCode != http.StatusOK {
+		err = errBadStatus(resp)
+		var statusErr *Error
+		if errors.As(err, &statusErr) && json.Unmarshal([]byte(statusErr.Body), &respObj) == nil && respObj.Error != "" {
+			statusErr.Body = fmt.Sprintf("%s: %s", respObj.ErrorType, respObj.Error)
+		}
+		return nil, err
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&respObj)
+	if err != nil {
+		return nil, err
+	}
+	if respObj.Status != "success" {
+		return nil, fmt.Errorf("query failed: %s: %s", respObj.ErrorType, respObj.Error)
+	}
+
+	var series []prometheusSeries
+
+	switch respObj.Data.ResultType {
+	case "vector", "matrix":
+		err = json.Unmarshal(respObj.Data.Result, &series)
+		if err != nil {
+			return nil, err
+		}
+	case "scalar":
+		var value []interface{}
+		err = json.Unmarshal(respObj.Data.Result, &value)
+		if err != nil {
+			return nil, err
+		}
+		series = []prometheusSeries{{Value: value}}
+	default:
+		return nil, fmt.Errorf("result type %q not supported", respObj.Data.ResultType)
+	}
+
+	var result []CarbonIntensity
+
+	for _, s := range series {
+		samples := s.Values
+		if s.Value != nil {
+			samples = append(samples, s.Value)
+		}
+
+		for _, sample := range samples {
+			validFrom, value, err := parsePrometheusSample(sample)
+			if err != nil {
+				return nil, err
+			}
+			if math.IsNaN(value) {
+				continue
+			}
+
+			result = append(result, CarbonIntensity{
+				EmissionsType: p.emissionsType,
+				MetricType:    p.metricType,
+				Provider:      p.name,
+				Location:      location,
+				Units:         p.units,
+				ValidFrom:     validFrom,
+				ValidTo:       validFrom.Add(p.step),
+				Value:         value,
+			})
+		}
+	}
+	if len(result) == 0 {
+		return nil, ErrNoResponse
+	}
+
+	return result, nil
+}
+
+// parsePrometheusSample parses a sample which is an array of a unix timestamp
+// in seconds and the value as a string.
+func parsePrometheusSample(sample []interface{}) (time.Time, float64, error) {
+	if len(sample) != 2 {
+		return time.Time{}, 0, fmt.Errorf("expected sample with 2 elements got %d", len(sample))
+	}
+
+	timestamp, ok := sample[0].(float64)
+	if !ok {
+		return time.Time{}, 0, fmt.Errorf("expected timestamp got %T", sample[0])
+	}
+	rawValue, ok := sample[1].(string)
+	if !ok {
+		return time.Time{}, 0, fmt.Errorf("expected value got %T", sample[1])
+	}
+	value, err := strconv.ParseFloat(rawValue, 64)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+
+	seconds, fraction := math.Modf(timestamp)
+	validFrom := time.Unix(int64(seconds), int64(math.Round(fraction*1e3))*int64(time.Millisecond)).UTC()
+
+	return validFrom, value, nil
+}
+
+func formatPrometheusTime(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixMilli())/1e3, 'f', 3, 64)
+}
@@ -0,0 +1,185 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var MockPrometheusQueryResponse = `{
+	"status": "success",
+	"data": {
+		"resultType": "vector",
+		"result": [
+			{
+				"metric": {"location": "UK"},
+				"value": [1717236900, "190"]
+			}
+		]
+	}
+}`
+
+var MockPrometheusQueryRangeResponse = `{
+	"status": "success",
+	"data": {
+		"resultType": "matrix",
+		"result": [
+			{
+				"metric": {"location": "UK"},
+				"values": [
+					[1717236000, "185.5"],
+					[1717236900, "NaN"],
+					[1717237800, "190"]
+				]
+			}
+		]
+	}
+}`
+
+var MockPrometheusErrorResponse = `{
+	"status": "error",
+	"errorType": "bad_data",
+	"error": "invalid parameter \"query\": parse error"
+}`
+
+func Test_Prometheus_SimpleRequest(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query" {
+			t.Errorf("unknown path %#q", r.URL.Path)
+		}
+		if query := r.URL.Query().Get("query"); query != `grid_intensity_carbon_average{location="UK"}` {
+			t.Errorf("unexpected query %#q", query)
+		}
+		if r.URL.Query().Get("time") == "" {
+			t.Errorf("time must be set")
+		}
+		fmt.Fprintln(w, MockPrometheusQueryResponse)
+	}))
+	defer ts.Close()
+
+	c := PrometheusConfig{
+		APIURL: ts.URL,
+		Query:  `grid_intensity_carbon_average{location="{location}"}`,
+	}
+	p, err := NewPrometheus(c)
+	if err != nil {
+		t.Fatalf("Could not make provider: %s", err)
+	}
+
+	res, err := p.GetCarbonIntensity(context.Background(), "UK")
+	if err != nil {
+		t.Fatalf("got error on GetCarbonIntensity: %s", err)
+	}
+
+	expected := []CarbonIntensity{
+		{
+			EmissionsType: "average",
+			MetricType:    "absolute",
+			Provider:      "Prometheus",
+			Location:      "UK",
+			Units:         "gCO2e per kWh",
+			ValidFrom:     time.Date(2024, 6, 1, 10, 15, 0, 0, time.UTC),
+			ValidTo:       time.Date(2024, 6, 1, 10, 20, 0, 0, time.UTC),
+			Value:         190,
+			IsEstimated:   false,
+		},
+	}
+	if !reflect.DeepEqual(expected, res) {
+		t.Errorf("want matching \n %s", cmp.Diff(res, expected))
+	}
+}
+
+func Test_Prometheus_RangeQuery(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/query_range" {
+			t.Errorf("unknown path %#q", r.URL.Path)
+		}
+		if step := r.URL.Query().Get("step"); step != "900" {
+			t.Errorf("expected step 900 got %#q", step)
+		}
+		// The location is escaped so it can't change the query.
+		if query := r.URL.Query().Get("query"); query != `intensity{zone="U\"K"}` {
+			t.Errorf("unexpected query %#q", query)
+		}
+		fmt.Fprintln(w, MockPrometheusQueryRangeResponse)
+	}))
+	defer ts.Close()
+
+	c := PrometheusConfig{
+		APIURL:        ts.URL,
+		Name:          "Internal",
+		Query:         `intensity{zone="{location}"}`,
+		Range:         time.Hour,
+		Step:          15 * time.Minute,
+		EmissionsType: "marginal",
+	}
+	p, err := NewPrometheus(c)
+	if err != nil {
+		t.Fatalf("Could not make provider: %s", err)
+	}
+
+	res, err := p.GetCarbonIntensity(context.Background(), `U"K`)
+	if err != nil {
+		t.Fatalf("got error on GetCarbonIntensity: %s", err)
+	}
+
+	// NaN values are skipped.
+	expected := []CarbonIntensity{
+		{
+			EmissionsType: "marginal",
+			MetricType:    "absolute",
+			Provider:      "Internal",
+			Location:      `U"K`,
+			Units:         "gCO2e per kWh",
+			ValidFrom:     time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC),
+			ValidTo:       time.Date(2024, 6, 1, 10, 15, 0, 0, time.UTC),
+			Value:         185.5,
+		},
+		{
+			EmissionsType: "marginal",
+			MetricType:    "absolute",
+			Provider:      "Internal",
+			Location:      `U"K`,
+			Units:         "gCO2e per kWh",
+			ValidFrom:     time.Date(2024, 6, 1, 10, 30, 0, 0, time.UTC),
+			ValidTo:       time.Date(2024, 6, 1, 10, 45, 0, 0, time.UTC),
+			Value:         190,
+		},
+	}
+	if !reflect.DeepEqual(expected, res) {
+		t.Errorf("want matching \n %s", cmp.Diff(res, expected))
+	}
+}
+
+func Test_Prometheus_Error(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintln(w, MockPrometheusErrorResponse)
+	}))
+	defer ts.Close()
+
+	p, err := NewPrometheus(PrometheusConfig{
+		APIURL: ts.URL,
+		Query:  "invalid{",
+	})
+	if err != nil {
+		t.Fatalf("Could not make provider: %s", err)
+	}
+
+	_, err = p.GetCarbonIntensity(context.Background(), "UK")
+	if !errors.Is(err, ErrReceivedNon200Status) {
+		t.Errorf("expected %v got %v", ErrReceivedNon200Status, err)
+	}
+	var providerErr *Error
+	if !errors.As(err, &providerErr) || providerErr.Provider != Prometheus || !strings.HasPrefix(providerErr.Body, "bad_data: ") {
+		t.Errorf("expected bad_data error for %s got %#v", Prometheus, err)
+	}
+}
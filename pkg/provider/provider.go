@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/url"
 	"path"
+	"sort"
 	"time"
 )
 
@@ -22,9 +23,18 @@ const (
 	Percent         = "percent"
 
 	// Supported providers
+	AEMO                 = "AEMO"
+	CarbonAwareSDK       = "CarbonAwareSDK"
 	CarbonIntensityOrgUK = "CarbonIntensityOrgUK"
+	EIA                  = "EIA"
 	ElectricityMaps      = "ElectricityMaps"
 	Ember                = "Ember"
+	EnergiDataService    = "EnergiDataService"
+	ENTSOE               = "ENTSOE"
+	File                 = "File"
+	JSONAPI              = "JSONAPI"
+	Prometheus           = "Prometheus"
+	RTE                  = "RTE"
 	WattTime             = "WattTime"
 )
 
@@ -51,10 +61,22 @@ type Interface interface {
 
 func GetProviderDetails() []Details {
 	return []Details{
+		{
+			Name: AEMO,
+			URL:  "aemo.com.au",
+		},
+		{
+			Name: CarbonAwareSDK,
+			URL:  "github.com/Green-Software-Foundation/carbon-aware-sdk",
+		},
 		{
 			Name: CarbonIntensityOrgUK,
 			URL:  "carbonintensity.org.uk",
 		},
+		{
+			Name: EIA,
+			URL:  "eia.gov",
+		},
 		{
 			Name: ElectricityMaps,
 			URL:  "electricitymaps.com",
@@ -63,6 +85,30 @@ func GetProviderDetails() []Details {
 			Name: Ember,
 			URL:  "ember-climate.org",
 		},
+		{
+			Name: EnergiDataService,
+			URL:  "energidataservice.dk",
+		},
+		{
+			Name: ENTSOE,
+			URL:  "transparency.entsoe.eu",
+		},
+		{
+			Name: File,
+			URL:  "local file",
+		},
+		{
+			Name: JSONAPI,
+			URL:  "configurable JSON API",
+		},
+		{
+			Name: Prometheus,
+			URL:  "prometheus.io",
+		},
+		{
+			Name: RTE,
+			URL:  "odre.opendatasoft.com",
+		},
 		{
 			Name: WattTime,
 			URL:  "watttime.org",
@@ -70,6 +116,78 @@ func GetProviderDetails() []Details {
 	}
 }
 
+// mergeEmissionFactors returns a copy of the default emission factors with
+// any overrides applied.
+func mergeEmissionFactors(defaults, overrides map[string]float64) map[string]float64 {
+	result := make(map[string]float64, len(defaults))
+	for fuel, factor := range defaults {
+		result[fuel] = factor
+	}
+	for fuel, factor := range overrides {
+		result[fuel] = factor
+	}
+
+	return result
+}
+
+// averageIntensity returns the generation weighted average of the emission
+// factors. Fuels without a factor use the factor for otherFuel. False is
+// returned if there is no generation.
+func averageIntensity(generation, factors map[string]float64, otherFuel string) (float64, bool) {
+	var totalGeneration, totalEmissions float64
+
+	// Sort the fuels so the result is deterministic.
+	fuels := make([]string, 0, len(generation))
+	for fuel := range generation {
+		fuels = append(fuels, fuel)
+	}
+	sort.Strings(fuels)
+
+	for _, fuel := range fuels {
+		quantity := generation[fuel]
+		if quantity <= 0 {
+			continue
+		}
+		factor, ok := factors[fuel]
+		if !ok {
+			factor = factors[otherFuel]
+		}
+		totalGeneration += quantity
+		totalEmissions += quantity * factor
+	}
+	if totalGeneration <= 0 {
+		return 0, false
+	}
+
+	return totalEmissions / totalGeneration, true
+}
+
+// latestCompletePeriod returns the index of the most recent period with
+// generation for every fuel in the period before it. Fuels are often
+// published at different times so the most recent periods may only have some
+// fuels, which would skew the average towards them. Returns -1 if there are
+// no periods.
+func latestCompletePeriod(periods []map[string]float64) int {
+	for i := len(periods) - 1; i > 0; i-- {
+		if hasFuels(periods[i], periods[i-1]) {
+			return i
+		}
+	}
+
+	return len(periods) - 1
+}
+
+// hasFuels returns true if generation has every fuel in previous.
+func hasFuels(generation, previous map[string]float64) bool {
+	for fuel := range previous {
+		if _, ok := generation[fuel]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
 func buildURL(apiURL, relativePath string) (string, error) {
 	baseURL, err := url.Parse(apiURL)
 	if err != nil {
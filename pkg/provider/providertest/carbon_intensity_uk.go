@@ -0,0 +1,54 @@
+package providertest
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/thegreenwebfoundation/grid-intensity-go/pkg/provider"
+)
+
+// CarbonIntensityUKResponse is the default response for the intensity
+// endpoint.
+const CarbonIntensityUKResponse = `{
+	"data": [
+		{
+			"from": "2020-01-01T00:00Z",
+			"to": "2020-01-01T00:30Z",
+			"intensity": {
+				"forecast": 186,
+				"actual": 190,
+				"index": "moderate"
+			}
+		}
+	]
+}`
+
+// CarbonIntensityUKServer is a fake UK Carbon Intensity API server.
+type CarbonIntensityUKServer struct {
+	*Server
+}
+
+func NewCarbonIntensityUKServer(t testing.TB) *CarbonIntensityUKServer {
+	s := &CarbonIntensityUKServer{}
+	s.Server = newServer(t, CarbonIntensityUKResponse, s.handle)
+
+	return s
+}
+
+// Config returns the config for a UK Carbon Intensity provider that uses
+// this server.
+func (s *CarbonIntensityUKServer) Config() provider.CarbonIntensityUKConfig {
+	return provider.CarbonIntensityUKConfig{
+		APIURL: s.URL,
+	}
+}
+
+func (s *CarbonIntensityUKServer) handle(w http.ResponseWriter, r *http.Request, response string) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	fmt.Fprintln(w, response)
+}
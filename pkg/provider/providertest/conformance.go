@@ -0,0 +1,137 @@
+package providertest
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/thegreenwebfoundation/grid-intensity-go/pkg/provider"
+)
+
+// Target is a provider to check with the conformance suite.
+type Target struct {
+	Provider provider.Interface
+	// Location is a location supported by the provider.
+	Location string
+	// InvalidLocation is optional. If set the provider must return
+	// provider.ErrInvalidLocation for it.
+	InvalidLocation string
+	// Network is set for providers that make requests. These must return an
+	// error when the context is cancelled.
+	Network bool
+}
+
+// Factory returns a new target for each test so state such as caches is not
+// shared between tests.
+type Factory func(t *testing.T) Target
+
+// RunConformance checks that a provider follows the contract of
+// provider.Interface.
+func RunConformance(t *testing.T, factory Factory) {
+	t.Helper()
+
+	t.Run("ValidResults", func(t *testing.T) {
+		target := factory(t)
+
+		result, err := target.Provider.GetCarbonIntensity(context.Background(), target.Location)
+		if err != nil {
+			t.Fatalf("GetCarbonIntensity(%q) returned error: %s", target.Location, err)
+		}
+		CheckResults(t, target.Location, result)
+	})
+
+	t.Run("InvalidLocation", func(t *testing.T) {
+		target := factory(t)
+		if target.InvalidLocation == "" {
+			t.Skip("no invalid location")
+		}
+
+		_, err := target.Provider.GetCarbonIntensity(context.Background(), target.InvalidLocation)
+		if !errors.Is(err, provider.ErrInvalidLocation) {
+			t.Errorf("GetCarbonIntensity(%q) expected %v got %v", target.InvalidLocation, provider.ErrInvalidLocation, err)
+		}
+	})
+
+	t.Run("CancelledContext", func(t *testing.T) {
+		target := factory(t)
+		if !target.Network {
+			t.Skip("provider does not make requests")
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := target.Provider.GetCarbonIntensity(ctx, target.Location)
+		if err == nil {
+			t.Errorf("GetCarbonIntensity(%q) expected error for cancelled context", target.Location)
+		}
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		target := factory(t)
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 5)
+
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := target.Provider.GetCarbonIntensity(context.Background(), target.Location)
+				errs <- err
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			if err != nil {
+				t.Errorf("GetCarbonIntensity(%q) returned error: %s", target.Location, err)
+			}
+		}
+	})
+}
+
+// CheckResults checks the results returned by a provider for a location are
+// valid.
+func CheckResults(t *testing.T, location string, result []provider.CarbonIntensity) {
+	t.Helper()
+
+	if len(result) == 0 {
+		t.Errorf("expected at least 1 result for %q", location)
+	}
+
+	for i, data := range result {
+		if data.Provider == "" {
+			t.Errorf("result %d: provider must be set", i)
+		}
+		if data.Location != location {
+			t.Errorf("result %d: expected location %q got %q", i, location, data.Location)
+		}
+		switch data.EmissionsType {
+		case provider.AverageEmissionsType, provider.MarginalEmissionsType:
+		default:
+			t.Errorf("result %d: unknown emissions type %q", i, data.EmissionsType)
+		}
+		switch data.MetricType {
+		case provider.AbsoluteMetricType:
+			if data.Units == "" || data.Units == provider.Percent {
+				t.Errorf("result %d: unexpected units %q for absolute metric", i, data.Units)
+			}
+		case provider.RelativeMetricType:
+			if data.Units != provider.Percent {
+				t.Errorf("result %d: expected units %q for relative metric got %q", i, provider.Percent, data.Units)
+			}
+		default:
+			t.Errorf("result %d: unknown metric type %q", i, data.MetricType)
+		}
+		if data.ValidFrom.IsZero() || !data.ValidFrom.Before(data.ValidTo) {
+			t.Errorf("result %d: valid from %s must be before valid to %s", i, data.ValidFrom, data.ValidTo)
+		}
+		if math.IsNaN(data.Value) || math.IsInf(data.Value, 0) || data.Value < 0 {
+			t.Errorf("result %d: invalid value %f", i, data.Value)
+		}
+	}
+}
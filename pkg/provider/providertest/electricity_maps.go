@@ -0,0 +1,70 @@
+package providertest
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/thegreenwebfoundation/grid-intensity-go/pkg/provider"
+)
+
+const (
+	// ElectricityMapsToken is the token accepted by the fake Electricity
+	// Maps server.
+	ElectricityMapsToken = "token"
+
+	// ElectricityMapsHistoryResponse is the default response for the carbon
+	// intensity history endpoint.
+	ElectricityMapsHistoryResponse = `{
+	"zone": "IN-KA",
+	"history": [
+		{
+			"zone": "IN-KA",
+			"carbonIntensity": 312,
+			"datetime": "2020-01-01T00:00:00.000Z",
+			"updatedAt": "2020-01-01T00:00:01.000Z",
+			"isEstimated": true
+		}
+	]
+}`
+)
+
+// ElectricityMapsServer is a fake Electricity Maps API server.
+type ElectricityMapsServer struct {
+	*Server
+}
+
+func NewElectricityMapsServer(t testing.TB) *ElectricityMapsServer {
+	s := &ElectricityMapsServer{}
+	s.Server = newServer(t, ElectricityMapsHistoryResponse, s.handle)
+
+	return s
+}
+
+// Config returns the config for an Electricity Maps provider that uses this
+// server.
+func (s *ElectricityMapsServer) Config() provider.ElectricityMapsConfig {
+	return provider.ElectricityMapsConfig{
+		APIURL: s.URL,
+		Token:  ElectricityMapsToken,
+	}
+}
+
+func (s *ElectricityMapsServer) handle(w http.ResponseWriter, r *http.Request, response string) {
+	if r.URL.Path != "/carbon-intensity/history" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if r.Header.Get("auth-token") != ElectricityMapsToken {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprintln(w, `{"error":"invalid auth-token"}`)
+		return
+	}
+	if r.URL.Query().Get("zone") == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintln(w, `{"error":"zone must be set"}`)
+		return
+	}
+
+	fmt.Fprintln(w, response)
+}
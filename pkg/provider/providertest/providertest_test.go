@@ -0,0 +1,126 @@
+package providertest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thegreenwebfoundation/grid-intensity-go/pkg/provider"
+)
+
+func Test_Server_SetError(t *testing.T) {
+	s := NewCarbonIntensityUKServer(t)
+	config := s.Config()
+	config.Retry = provider.RetryConfig{
+		InitialBackoff: time.Millisecond,
+	}
+	c, err := provider.NewCarbonIntensityUK(config)
+	if err != nil {
+		t.Fatalf("Could not make provider: %s", err)
+	}
+
+	s.SetError(http.StatusInternalServerError, "internal error")
+
+	_, err = c.GetCarbonIntensity(context.Background(), "UK")
+	if !errors.Is(err, provider.ErrReceivedNon200Status) {
+		t.Errorf("expected %v got %v", provider.ErrReceivedNon200Status, err)
+	}
+	if err != nil && !strings.Contains(err.Error(), "internal error") {
+		t.Errorf("expected error body in %q", err)
+	}
+
+	s.Reset()
+
+	_, err = c.GetCarbonIntensity(context.Background(), "UK")
+	if err != nil {
+		t.Errorf("expected no error after reset got %v", err)
+	}
+	// The error is retried 3 times before giving up.
+	if s.Requests() != 4 {
+		t.Errorf("expected 4 requests got %d", s.Requests())
+	}
+}
+
+func Test_Server_SetResponse(t *testing.T) {
+	s := NewElectricityMapsServer(t)
+	c, err := provider.NewElectricityMaps(s.Config())
+	if err != nil {
+		t.Fatalf("Could not make provider: %s", err)
+	}
+
+	s.SetResponse(`{"zone":"IN-KA","history":[{"zone":"IN-KA","carbonIntensity":400,"datetime":"2020-01-01T01:00:00.000Z","isEstimated":false}]}`)
+
+	result, err := c.GetCarbonIntensity(context.Background(), "IN-KA")
+	if err != nil {
+		t.Fatalf("got error on GetCarbonIntensity: %s", err)
+	}
+	if len(result) != 1 || result[0].Value != 400 || result[0].IsEstimated {
+		t.Errorf("unexpected result %v", result)
+	}
+}
+
+func Test_Server_SetLatency(t *testing.T) {
+	s := NewCarbonIntensityUKServer(t)
+	c, err := provider.NewCarbonIntensityUK(s.Config())
+	if err != nil {
+		t.Fatalf("Could not make provider: %s", err)
+	}
+
+	s.SetLatency(time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = c.GetCarbonIntensity(ctx, "UK")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected %v got %v", context.DeadlineExceeded, err)
+	}
+}
+
+func Test_Server_SetRateLimit(t *testing.T) {
+	s := NewCarbonIntensityUKServer(t)
+
+	s.SetRateLimit(1, 1500*time.Millisecond)
+
+	for i, expected := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		resp, err := http.Get(s.URL)
+		if err != nil {
+			t.Fatalf("request %d failed: %s", i, err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != expected {
+			t.Errorf("request %d: expected status %d got %d", i, expected, resp.StatusCode)
+		}
+		if expected == http.StatusTooManyRequests && resp.Header.Get("Retry-After") != "2" {
+			t.Errorf("expected retry after 2 got %q", resp.Header.Get("Retry-After"))
+		}
+	}
+}
+
+func Test_WattTimeServer_ExpireToken(t *testing.T) {
+	s := NewWattTimeServer(t)
+	c, err := provider.NewWattTime(s.Config())
+	if err != nil {
+		t.Fatalf("Could not make provider: %s", err)
+	}
+
+	_, err = c.GetCarbonIntensity(context.Background(), "CAISO_NORTH")
+	if err != nil {
+		t.Fatalf("got error on GetCarbonIntensity: %s", err)
+	}
+
+	// The result is cached so use a different location.
+	s.ExpireToken()
+
+	_, err = c.GetCarbonIntensity(context.Background(), "CAISO_SOUTH")
+	if err != nil {
+		t.Fatalf("got error on GetCarbonIntensity: %s", err)
+	}
+	if s.Logins() != 2 {
+		t.Errorf("expected 2 logins got %d", s.Logins())
+	}
+}
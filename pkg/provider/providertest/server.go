@@ -0,0 +1,139 @@
+// Package providertest provides fake provider API servers and a conformance
+// suite for testing implementations of provider.Interface.
+package providertest
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Server is a fake provider API server. The response, errors, latency and
+// rate limiting can be changed while the server is running.
+type Server struct {
+	*httptest.Server
+
+	handler func(w http.ResponseWriter, r *http.Request, response string)
+
+	mu         sync.Mutex
+	response   string
+	requests   int
+	latency    time.Duration
+	errStatus  int
+	errBody    string
+	rateLimit  int
+	retryAfter time.Duration
+	limited    bool
+}
+
+// newServer starts a server that is closed when the test finishes. The
+// handler is called with the current response when no error is injected.
+func newServer(t testing.TB, response string, handler func(w http.ResponseWriter, r *http.Request, response string)) *Server {
+	s := &Server{
+		handler:  handler,
+		response: response,
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+// SetResponse sets the body returned for successful data requests.
+func (s *Server) SetResponse(body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.response = body
+}
+
+// SetError makes all requests return the status code and body until Reset
+// is called.
+func (s *Server) SetError(status int, body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.errStatus = status
+	s.errBody = body
+}
+
+// SetLatency delays each response. The delay ends early if the request is
+// cancelled.
+func (s *Server) SetLatency(latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.latency = latency
+}
+
+// SetRateLimit makes requests after the first limit requests return 429 Too
+// Many Requests with a Retry-After header. A limit of 0 rate limits all
+// requests.
+func (s *Server) SetRateLimit(limit int, retryAfter time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.limited = true
+	s.rateLimit = s.requests + limit
+	s.retryAfter = retryAfter
+}
+
+// Reset removes any injected errors, latency and rate limits.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.errStatus = 0
+	s.errBody = ""
+	s.latency = 0
+	s.limited = false
+}
+
+// Requests returns the number of requests received.
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests++
+	requests := s.requests
+	response := s.response
+	latency := s.latency
+	errStatus := s.errStatus
+	errBody := s.errBody
+	limited := s.limited && requests > s.rateLimit
+	retryAfter := s.retryAfter
+	s.mu.Unlock()
+
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if limited {
+		seconds := int(math.Ceil(retryAfter.Seconds()))
+		w.Header().Set("Retry-After", strconv.Itoa(seconds))
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprintln(w, `{"error":"rate limit exceeded"}`)
+		return
+	}
+	if errStatus != 0 {
+		w.WriteHeader(errStatus)
+		fmt.Fprintln(w, errBody)
+		return
+	}
+
+	s.handler(w, r, response)
+}
@@ -0,0 +1,102 @@
+package providertest
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/thegreenwebfoundation/grid-intensity-go/pkg/provider"
+)
+
+const (
+	// WattTimeUser and WattTimePassword are the credentials accepted by the
+	// fake WattTime server.
+	WattTimeUser     = "user"
+	WattTimePassword = "password"
+
+	// WattTimeIndexResponse is the default response for the index endpoint.
+	WattTimeIndexResponse = `{
+	"ba": "CAISO_NORTH",
+	"freq": "300",
+	"moer": "916",
+	"percent": "78",
+	"point_time": "2022-07-06T16:25:00Z"
+}`
+)
+
+// WattTimeServer is a fake WattTime API server. Tokens are issued by the
+// login endpoint and must be sent to the index endpoint.
+type WattTimeServer struct {
+	*Server
+
+	mu     sync.Mutex
+	tokens int
+	logins int
+}
+
+func NewWattTimeServer(t testing.TB) *WattTimeServer {
+	s := &WattTimeServer{}
+	s.Server = newServer(t, WattTimeIndexResponse, s.handle)
+
+	return s
+}
+
+// Config returns the config for a WattTime provider that uses this server.
+func (s *WattTimeServer) Config() provider.WattTimeConfig {
+	return provider.WattTimeConfig{
+		APIURL:      s.URL,
+		APIUser:     WattTimeUser,
+		APIPassword: WattTimePassword,
+	}
+}
+
+// ExpireToken makes the current token invalid so the index endpoint returns
+// 403 Forbidden until the client logs in again.
+func (s *WattTimeServer) ExpireToken() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens++
+}
+
+// Logins returns the number of successful logins.
+func (s *WattTimeServer) Logins() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.logins
+}
+
+func (s *WattTimeServer) token() string {
+	return fmt.Sprintf("token-%d", s.tokens)
+}
+
+func (s *WattTimeServer) handle(w http.ResponseWriter, r *http.Request, response string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch r.URL.Path {
+	case "/login":
+		user, password, ok := r.BasicAuth()
+		if !ok || user != WattTimeUser || password != WattTimePassword {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		s.logins++
+		fmt.Fprintf(w, `{"token":%q}`, s.token())
+	case "/index":
+		if r.Header.Get("Authorization") != "Bearer "+s.token() {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Query().Get("ba") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintln(w, `{"error":"ba must be set"}`)
+			return
+		}
+		fmt.Fprintln(w, response)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
@@ -0,0 +1,166 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	// Supported estimate preferences.
+	PreferActual    = "actual"
+	PreferEstimated = "estimated"
+)
+
+// Query is a request for carbon intensity data for a location.
+type Query struct {
+	Location string
+	// EmissionsType is average or marginal. Optional, all emissions types are
+	// returned if empty.
+	EmissionsType string
+	// MetricType is absolute or relative. Optional, all metric types are
+	// returned if empty.
+	MetricType string
+	// At is the time the data should be valid at. Optional, the current data
+	// is returned if zero.
+	At time.Time
+	// Prefer is actual or estimated. When both actual and estimated values are
+	// returned only the preferred values are kept. Optional.
+	Prefer string
+}
+
+// QueryInterface is implemented by providers that support queries using
+// their API, e.g. to get the data for a time in the past.
+type QueryInterface interface {
+	Interface
+	GetCarbonIntensityQuery(ctx context.Context, query Query) ([]CarbonIntensity, error)
+}
+
+// GetCarbonIntensityQuery returns the data for the query. Providers that
+// implement QueryInterface are called directly. For other providers the
+// current data is filtered so times are only supported if they are covered by
+// the results, e.g. forecasts. Past times are only supported by providers
+// that implement QueryInterface.
+func GetCarbonIntensityQuery(ctx context.Context, client Interface, query Query) ([]CarbonIntensity, error) {
+	err := query.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	if q, ok := client.(QueryInterface); ok {
+		return q.GetCarbonIntensityQuery(ctx, query)
+	}
+
+	data, err := client.GetCarbonIntensity(ctx, query.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	var providerName string
+	if len(data) > 0 {
+		providerName = data[0].Provider
+	}
+
+	return filterQuery(providerName, query, data, true)
+}
+
+func (q Query) validate() error {
+	switch q.EmissionsType {
+	case "", AverageEmissionsType, MarginalEmissionsType:
+	default:
+		return fmt.Errorf("invalid emissions type %q, must be %s or %s", q.EmissionsType, AverageEmissionsType, MarginalEmissionsType)
+	}
+	switch q.MetricType {
+	case "", AbsoluteMetricType, RelativeMetricType:
+	default:
+		return fmt.Errorf("invalid metric type %q, must be %s or %s", q.MetricType, AbsoluteMetricType, RelativeMetricType)
+	}
+	switch q.Prefer {
+	case "", PreferActual, PreferEstimated:
+	default:
+		return fmt.Errorf("invalid preference %q, must be %s or %s", q.Prefer, PreferActual, PreferEstimated)
+	}
+
+	return nil
+}
+
+// filterQuery returns the data matching the emissions and metric types of the
+// query. If filterTime is true only data valid at the query time is returned.
+// Providers that requested the data for the time set it to false. Errors are
+// returned as an *Error for the provider and location.
+func filterQuery(provider string, query Query, data []CarbonIntensity, filterTime bool) ([]CarbonIntensity, error) {
+	filterTime = filterTime && !query.At.IsZero()
+
+	var result []CarbonIntensity
+	var emissionsTypeFound, metricTypeFound bool
+
+	for _, point := range data {
+		if query.EmissionsType != "" && point.EmissionsType != query.EmissionsType {
+			continue
+		}
+		emissionsTypeFound = true
+
+		if query.MetricType != "" && point.MetricType != query.MetricType {
+			continue
+		}
+		metricTypeFound = true
+
+		if filterTime && (point.ValidFrom.After(query.At) || !point.ValidTo.After(query.At)) {
+			continue
+		}
+		result = append(result, point)
+	}
+
+	var err error
+	switch {
+	case query.EmissionsType != "" && !emissionsTypeFound:
+		err = errNoEmissionsType(query.EmissionsType)
+	case query.MetricType != "" && !metricTypeFound:
+		err = errNoMetricType(query.MetricType)
+	case filterTime && len(result) == 0:
+		err = fmt.Errorf("%w %s", ErrNoIntensityPresentForTime, query.At.UTC().Format(time.RFC3339))
+	}
+	if err != nil {
+		return nil, newError(provider, query.Location, err)
+	}
+
+	return preferEstimates(result, query.Prefer), nil
+}
+
+// preferEstimates returns only the actual or estimated values if there are
+// any. Otherwise all the data is returned.
+func preferEstimates(data []CarbonIntensity, prefer string) []CarbonIntensity {
+	if prefer == "" {
+		return data
+	}
+
+	estimated := prefer == PreferEstimated
+
+	var result []CarbonIntensity
+	for _, point := range data {
+		if point.IsEstimated == estimated {
+			result = append(result, point)
+		}
+	}
+	if len(result) == 0 {
+		return data
+	}
+
+	return result
+}
+
+func errNoEmissionsType(emissionsType string) error {
+	if emissionsType == MarginalEmissionsType {
+		return ErrNoMarginalIntensityPresent
+	}
+
+	return ErrNoAverageIntensityPresent
+}
+
+func errNoMetricType(metricType string) error {
+	if metricType == RelativeMetricType {
+		return ErrNoRelativeIntensityPresent
+	}
+
+	return ErrNoAbsoluteIntensityPresent
+}
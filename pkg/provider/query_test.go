@@ -0,0 +1,146 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakeQueryProvider returns the same data for every location.
+type fakeQueryProvider struct {
+	data []CarbonIntensity
+}
+
+func (f fakeQueryProvider) GetCarbonIntensity(ctx context.Context, location string) ([]CarbonIntensity, error) {
+	return f.data, nil
+}
+
+func Test_GetCarbonIntensityQuery(t *testing.T) {
+	hour := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	relative := CarbonIntensity{
+		EmissionsType: MarginalEmissionsType,
+		MetricType:    RelativeMetricType,
+		Provider:      WattTime,
+		Location:      "CAISO_NORTH",
+		Units:         Percent,
+		ValidFrom:     hour,
+		ValidTo:       hour.Add(5 * time.Minute),
+		Value:         80,
+	}
+	absolute := CarbonIntensity{
+		EmissionsType: MarginalEmissionsType,
+		MetricType:    AbsoluteMetricType,
+		Provider:      WattTime,
+		Location:      "CAISO_NORTH",
+		Units:         LbCO2EPerMWh,
+		ValidFrom:     hour,
+		ValidTo:       hour.Add(5 * time.Minute),
+		Value:         900,
+	}
+	forecast := absolute
+	forecast.ValidFrom = hour.Add(5 * time.Minute)
+	forecast.ValidTo = hour.Add(10 * time.Minute)
+	forecast.Value = 850
+	forecast.IsEstimated = true
+
+	p := fakeQueryProvider{
+		data: []CarbonIntensity{relative, absolute, forecast},
+	}
+
+	tests := []struct {
+		name     string
+		query    Query
+		expected []CarbonIntensity
+		err      error
+	}{
+		{
+			name:     "all data",
+			query:    Query{Location: "CAISO_NORTH"},
+			expected: []CarbonIntensity{relative, absolute, forecast},
+		},
+		{
+			name: "relative",
+			query: Query{
+				Location:      "CAISO_NORTH",
+				EmissionsType: MarginalEmissionsType,
+				MetricType:    RelativeMetricType,
+			},
+			expected: []CarbonIntensity{relative},
+		},
+		{
+			name: "forecast time",
+			query: Query{
+				Location: "CAISO_NORTH",
+				At:       hour.Add(7 * time.Minute),
+			},
+			expected: []CarbonIntensity{forecast},
+		},
+		{
+			name: "prefer actual",
+			query: Query{
+				Location:   "CAISO_NORTH",
+				MetricType: AbsoluteMetricType,
+				Prefer:     PreferActual,
+			},
+			expected: []CarbonIntensity{absolute},
+		},
+		{
+			name: "prefer actual when only estimated",
+			query: Query{
+				Location: "CAISO_NORTH",
+				At:       hour.Add(7 * time.Minute),
+				Prefer:   PreferActual,
+			},
+			expected: []CarbonIntensity{forecast},
+		},
+		{
+			name: "no average",
+			query: Query{
+				Location:      "CAISO_NORTH",
+				EmissionsType: AverageEmissionsType,
+			},
+			err: ErrNoAverageIntensityPresent,
+		},
+		{
+			name: "no data for time",
+			query: Query{
+				Location: "CAISO_NORTH",
+				At:       hour.Add(-time.Hour),
+			},
+			err: ErrNoIntensityPresentForTime,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := GetCarbonIntensityQuery(context.Background(), p, tc.query)
+			if tc.err != nil {
+				if !errors.Is(err, tc.err) {
+					t.Fatalf("expected error %v got %v", tc.err, err)
+				}
+				var providerErr *Error
+				if !errors.As(err, &providerErr) || providerErr.Category != ErrorCategoryNotFound || providerErr.Provider != WattTime {
+					t.Errorf("expected not found error for %s got %#v", WattTime, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error == %#v want nil", err)
+			}
+			if !reflect.DeepEqual(tc.expected, result) {
+				t.Errorf("want matching \n %s", cmp.Diff(result, tc.expected))
+			}
+		})
+	}
+
+	_, err := GetCarbonIntensityQuery(context.Background(), p, Query{Location: "CAISO_NORTH", EmissionsType: "unknown"})
+	if err == nil {
+		t.Errorf("expected error for invalid emissions type")
+	}
+
+}
@@ -0,0 +1,203 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gofrs/flock"
+)
+
+// RateLimitConfig limits the requests made to a provider API so quotas are
+// not exceeded. Requests over the limits fail with ErrQuotaExceeded without
+// calling the API.
+type RateLimitConfig struct {
+	// RequestsPerMinute is the rate requests are allowed using a token
+	// bucket. Optional.
+	RequestsPerMinute float64
+	// Burst is the number of requests that can be made at once. Defaults to
+	// 1.
+	Burst int
+	// DailyQuota is the number of requests allowed per UTC day. Optional.
+	DailyQuota int
+	// QuotaFile is optional and is used to persist the requests made today
+	// so the quota is shared between runs and processes.
+	QuotaFile string
+}
+
+// QuotaReporter is implemented by providers that have a daily quota.
+type QuotaReporter interface {
+	// RemainingQuota returns the number of requests remaining today. False
+	// is returned if there is no daily quota.
+	RemainingQuota() (int, bool)
+}
+
+type rateLimiter struct {
+	ratePerSecond float64
+	burst         float64
+	dailyQuota    int
+	quotaFile     string
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	usage  quotaUsage
+}
+
+type quotaUsage struct {
+	Day      string `json:"day"`
+	Requests int    `json:"requests"`
+}
+
+// newRateLimiter returns nil if no limits are configured.
+func newRateLimiter(config RateLimitConfig) *rateLimiter {
+	if config.RequestsPerMinute <= 0 && config.DailyQuota <= 0 {
+		return nil
+	}
+	if config.Burst <= 0 {
+		config.Burst = 1
+	}
+
+	return &rateLimiter{
+		ratePerSecond: config.RequestsPerMinute / 60,
+		burst:         float64(config.Burst),
+		dailyQuota:    config.DailyQuota,
+		quotaFile:     config.QuotaFile,
+		tokens:        float64(config.Burst),
+	}
+}
+
+// allow returns ErrQuotaExceeded if the request is over the rate limit or
+// the daily quota. Otherwise the request is counted.
+func (l *rateLimiter) allow(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+
+	if l.ratePerSecond > 0 {
+		if !l.last.IsZero() {
+			l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.ratePerSecond)
+		}
+		l.last = now
+
+		if l.tokens < 1 {
+			wait := time.Duration((1 - l.tokens) / l.ratePerSecond * float64(time.Second))
+			return fmt.Errorf("rate limit of %g requests per minute, retry in %s: %w",
+				l.ratePerSecond*60, wait.Round(time.Second), ErrQuotaExceeded)
+		}
+	}
+
+	if l.dailyQuota > 0 {
+		err := l.updateUsage(ctx, now, func(usage *quotaUsage) error {
+			if usage.Requests >= l.dailyQuota {
+				return fmt.Errorf("daily quota of %d requests used: %w", l.dailyQuota, ErrQuotaExceeded)
+			}
+			usage.Requests++
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if l.ratePerSecond > 0 {
+		l.tokens--
+	}
+
+	return nil
+}
+
+// RemainingQuota returns the requests remaining today.
+func (l *rateLimiter) RemainingQuota() (int, bool) {
+	if l == nil || l.dailyQuota <= 0 {
+		return 0, false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var used int
+	err := l.updateUsage(context.Background(), time.Now(), func(usage *quotaUsage) error {
+		used = usage.Requests
+		return nil
+	})
+	if err != nil {
+		used = l.usage.Requests
+	}
+
+	remaining := l.dailyQuota - used
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return remaining, true
+}
+
+// updateUsage calls update with the usage for today. If there is a quota
+// file the usage is loaded and saved while holding a file lock.
+func (l *rateLimiter) updateUsage(ctx context.Context, now time.Time, update func(*quotaUsage) error) error {
+	day := now.UTC().Format("2006-01-02")
+
+	if l.quotaFile == "" {
+		if l.usage.Day != day {
+			l.usage = quotaUsage{Day: day}
+		}
+		return update(&l.usage)
+	}
+
+	err := os.MkdirAll(filepath.Dir(l.quotaFile), os.ModePerm)
+	if err != nil && !os.IsExist(err) {
+		return err
+	}
+
+	lockCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	fileLock := flock.New(l.quotaFile + ".lock")
+	locked, err := fileLock.TryLockContext(lockCtx, 100*time.Millisecond)
+	if err == nil && locked {
+		defer fileLock.Unlock()
+	}
+	if err != nil {
+		return err
+	}
+
+	usage := quotaUsage{}
+	data, err := os.ReadFile(l.quotaFile)
+	if err == nil {
+		err = json.Unmarshal(data, &usage)
+		if err != nil {
+			return fmt.Errorf("could not parse quota file %s: %w", l.quotaFile, err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if usage.Day != day {
+		usage = quotaUsage{Day: day}
+	}
+
+	before := usage
+	err = update(&usage)
+	if err != nil {
+		return err
+	}
+	l.usage = usage
+
+	if usage == before {
+		return nil
+	}
+
+	data, err = json.Marshal(usage)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(l.quotaFile, data, 0644)
+}
@@ -0,0 +1,158 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_RateLimit_RequestsPerMinute(t *testing.T) {
+	var requests int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		fmt.Fprintln(w, MockElectricityMapResponse)
+	}))
+	defer ts.Close()
+
+	c := ElectricityMapsConfig{
+		APIURL: ts.URL,
+		RateLimit: RateLimitConfig{
+			RequestsPerMinute: 1,
+			Burst:             2,
+		},
+	}
+	e, err := NewElectricityMaps(c)
+	if err != nil {
+		t.Fatalf("Could not make provider: %s", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err = e.GetCarbonIntensity(context.Background(), "IN-KA")
+		if err != nil {
+			t.Fatalf("got error on GetCarbonIntensity: %s", err)
+		}
+	}
+
+	// The bucket is empty so the API is not called.
+	_, err = e.GetCarbonIntensity(context.Background(), "IN-KA")
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("expected %v got %v", ErrQuotaExceeded, err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("expected 2 requests got %d", n)
+	}
+}
+
+func Test_RateLimit_DailyQuota(t *testing.T) {
+	var requests int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		fmt.Fprintln(w, MockElectricityMapResponse)
+	}))
+	defer ts.Close()
+
+	c := ElectricityMapsConfig{
+		APIURL: ts.URL,
+		RateLimit: RateLimitConfig{
+			DailyQuota: 3,
+			QuotaFile:  filepath.Join(t.TempDir(), "quota.json"),
+		},
+	}
+	first, err := NewElectricityMaps(c)
+	if err != nil {
+		t.Fatalf("Could not make provider: %s", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err = first.GetCarbonIntensity(context.Background(), "IN-KA")
+		if err != nil {
+			t.Fatalf("got error on GetCarbonIntensity: %s", err)
+		}
+	}
+
+	// The usage is persisted so it is shared with new clients.
+	second, err := NewElectricityMaps(c)
+	if err != nil {
+		t.Fatalf("Could not make provider: %s", err)
+	}
+
+	reporter, ok := second.(QuotaReporter)
+	if !ok {
+		t.Fatalf("expected %T to implement QuotaReporter", second)
+	}
+	if remaining, ok := reporter.RemainingQuota(); !ok || remaining != 1 {
+		t.Errorf("expected 1 remaining got %d", remaining)
+	}
+
+	_, err = second.GetCarbonIntensity(context.Background(), "IN-KA")
+	if err != nil {
+		t.Fatalf("got error on GetCarbonIntensity: %s", err)
+	}
+	_, err = second.GetCarbonIntensity(context.Background(), "IN-KA")
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("expected %v got %v", ErrQuotaExceeded, err)
+	}
+	if remaining, _ := reporter.RemainingQuota(); remaining != 0 {
+		t.Errorf("expected 0 remaining got %d", remaining)
+	}
+	if n := atomic.LoadInt32(&requests); n != 3 {
+		t.Errorf("expected 3 requests got %d", n)
+	}
+}
+
+func Test_RateLimit_Retry(t *testing.T) {
+	var requests int32
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusBadGateway)
+		fmt.Fprintln(w, "bad gateway")
+	}))
+	defer ts.Close()
+
+	c := CarbonIntensityUKConfig{
+		APIURL: ts.URL,
+		Retry: RetryConfig{
+			InitialBackoff: time.Millisecond,
+		},
+		RateLimit: RateLimitConfig{
+			RequestsPerMinute: 1,
+			Burst:             2,
+		},
+	}
+	a, err := NewCarbonIntensityUK(c)
+	if err != nil {
+		t.Fatalf("Could not make provider: %s", err)
+	}
+
+	// Each attempt counts towards the rate limit so the third attempt is not
+	// sent and the upstream error is returned.
+	_, err = a.GetCarbonIntensity(context.Background(), "UK")
+	if errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("expected upstream error got %v", err)
+	}
+	var providerErr *Error
+	if !errors.As(err, &providerErr) || providerErr.StatusCode != http.StatusBadGateway || providerErr.Attempts != 2 {
+		t.Errorf("expected %d error after 2 attempts got %#v", http.StatusBadGateway, err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("expected 2 requests got %d", n)
+	}
+
+	// The limit is used up so the next request is not sent.
+	_, err = a.GetCarbonIntensity(context.Background(), "UK")
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Errorf("expected %v got %v", ErrQuotaExceeded, err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("expected 2 requests got %d", n)
+	}
+}
@@ -0,0 +1,189 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type RTEClient struct {
+	*httpClient
+
+	apiURL          string
+	dataset         string
+	forecastDataset string
+	disableForecast bool
+}
+
+type RTEConfig struct {
+	Client    *http.Client
+	Retry     RetryConfig
+	RateLimit RateLimitConfig
+	Logger    *slog.Logger
+	APIURL    string
+	// Dataset is the ODRE dataset with realised eCO2mix data.
+	Dataset string
+	// ForecastDataset is the ODRE dataset with forecast eCO2mix data. It
+	// defaults to Dataset.
+	ForecastDataset string
+	// DisableForecast only requests the realised data.
+	DisableForecast bool
+}
+
+func NewRTE(config RTEConfig) (Interface, error) {
+	if config.Client == nil {
+		config.Client = &http.Client{
+			Timeout: 5 * time.Second,
+		}
+	}
+	if config.APIURL == "" {
+		config.APIURL = "https://odre.opendatasoft.com/api/explore/v2.1"
+	}
+	if config.Dataset == "" {
+		config.Dataset = "eco2mix-national-tr"
+	}
+	if config.ForecastDataset == "" {
+		config.ForecastDataset = config.Dataset
+	}
+
+	c := &RTEClient{
+		httpClient:      newHTTPClient(config.Client, config.Retry, config.RateLimit, newLogger(config.Logger, RTE)),
+		apiURL:          config.APIURL,
+		dataset:         config.Dataset,
+		forecastDataset: config.ForecastDataset,
+		disableForecast: config.DisableForecast,
+	}
+
+	return c, nil
+}
+
+// GetCarbonIntensity returns the latest realised 15 minute CO2 rate followed
+// by the forecast values for the next day unless the forecast is disabled.
+func (r *RTEClient) GetCarbonIntensity(ctx context.Context, location string) ([]CarbonIntensity, error) {
+	return r.coalesce(ctx, RTE, location, r.getCarbonIntensity)
+}
+
+func (r *RTEClient) getCarbonIntensity(ctx context.Context, location string) ([]CarbonIntensity, error) {
+	location = strings.ToUpper(location)
+	if location != "FR" {
+		return nil, ErrInvalidLocation
+	}
+
+	now := time.Now().UTC()
+
+	realised, err := r.getRecords(ctx, r.dataset, url.Values{
+		"where":    []string{fmt.Sprintf("taux_co2 is not null and date_heure <= date'%s'", now.Format(time.RFC3339))},
+		"order_by": []string{"date_heure desc"},
+		"limit":    []string{"1"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var forecast []rteRecord
+	if !r.disableForecast {
+		forecast, err = r.getRecords(ctx, r.forecastDataset, url.Values{
+			"where": []string{fmt.Sprintf("taux_co2 is not null and date_heure > date'%s' and date_heure <= date'%s'",
+				now.Format(time.RFC3339), now.Add(24*time.Hour).Format(time.RFC3339))},
+			"order_by": []string{"date_heure asc"},
+			"limit":    []string{"100"},
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(realised) == 0 && len(forecast) == 0 {
+		return nil, ErrNoResponse
+	}
+
+	var result []CarbonIntensity
+
+	for _, record := range realised {
+		point, err := record.toCarbonIntensity(location, false)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *point)
+	}
+	for _, record := range forecast {
+		point, err := record.toCarbonIntensity(location, true)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *point)
+	}
+
+	return result, nil
+}
+
+func (r *RTEClient) getRecords(ctx context.Context, dataset string, params url.Values) ([]rteRecord, error) {
+	recordsURL, err := r.recordsURL(dataset, params)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, recordsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errBadStatus(resp)
+	}
+
+	respObj := &rteResponse{}
+	err = json.NewDecoder(resp.Body).Decode(respObj)
+	if err != nil {
+		return nil, err
+	}
+
+	return respObj.Results, nil
+}
+
+func (r *RTEClient) recordsURL(dataset string, params url.Values) (string, error) {
+	params.Set("select", "date_heure,taux_co2")
+
+	return buildURL(r.apiURL, fmt.Sprintf("/catalog/datasets/%s/records?%s", dataset, params.Encode()))
+}
+
+func (r *rteRecord) toCarbonIntensity(location string, isEstimated bool) (*CarbonIntensity, error) {
+	validFrom, err := time.Parse(time.RFC3339, r.DateHeure)
+	if err != nil {
+		return nil, err
+	}
+	validFrom = validFrom.UTC()
+
+	return &CarbonIntensity{
+		EmissionsType: AverageEmissionsType,
+		MetricType:    AbsoluteMetricType,
+		Provider:      RTE,
+		Location:      location,
+		Units:         GramsCO2EPerkWh,
+		ValidFrom:     validFrom,
+		ValidTo:       validFrom.Add(15 * time.Minute),
+		Value:         r.TauxCO2,
+		IsEstimated:   isEstimated,
+	}, nil
+}
+
+type rteResponse struct {
+	TotalCount int         `json:"total_count"`
+	Results    []rteRecord `json:"results"`
+}
+
+type rteRecord struct {
+	DateHeure string  `json:"date_heure"`
+	TauxCO2   float64 `json:"taux_co2"`
+}
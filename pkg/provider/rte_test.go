@@ -0,0 +1,182 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var MockRTERealisedResponse = `{
+	"total_count": 1,
+	"results": [
+		{
+			"date_heure": "2024-06-01T10:15:00+00:00",
+			"taux_co2": 22
+		}
+	]
+}`
+
+var MockRTEForecastResponse = `{
+	"total_count": 2,
+	"results": [
+		{
+			"date_heure": "2024-06-01T12:30:00+02:00",
+			"taux_co2": 24
+		},
+		{
+			"date_heure": "2024-06-01T12:45:00+02:00",
+			"taux_co2": 25.5
+		}
+	]
+}`
+
+// makeRTETestServer returns a server that checks the dataset and where clause
+// of each request. Realised and forecast data are told apart by the where
+// clause as they can use the same dataset.
+func makeRTETestServer(t *testing.T, forecastDataset string) *httptest.Server {
+	realisedWhere := regexp.MustCompile(`^taux_co2 is not null and date_heure <= date'[^']+'$`)
+	forecastWhere := regexp.MustCompile(`^taux_co2 is not null and date_heure > date'[^']+' and date_heure <= date'[^']+'$`)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		where := r.URL.Query().Get("where")
+
+		switch {
+		case r.URL.Path == "/catalog/datasets/eco2mix-national-tr/records" && realisedWhere.MatchString(where):
+			fmt.Fprintln(w, MockRTERealisedResponse)
+		case r.URL.Path == "/catalog/datasets/"+forecastDataset+"/records" && forecastWhere.MatchString(where):
+			fmt.Fprintln(w, MockRTEForecastResponse)
+		default:
+			t.Errorf("unexpected request for %#q with where clause %#q", r.URL.Path, where)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func Test_RTE_SimpleRequest(t *testing.T) {
+	tests := []struct {
+		name            string
+		forecastDataset string
+		serverDataset   string
+	}{
+		{
+			// The forecast is requested from the realised dataset by default.
+			name:          "default forecast dataset",
+			serverDataset: "eco2mix-national-tr",
+		},
+		{
+			name:            "forecast dataset",
+			forecastDataset: "co2-forecast",
+			serverDataset:   "co2-forecast",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			testRTEForecast(t, tc.forecastDataset, tc.serverDataset)
+		})
+	}
+}
+
+func testRTEForecast(t *testing.T, forecastDataset, serverDataset string) {
+	ts := makeRTETestServer(t, serverDataset)
+	defer ts.Close()
+
+	c := RTEConfig{
+		APIURL:          ts.URL,
+		ForecastDataset: forecastDataset,
+	}
+	e, err := NewRTE(c)
+	if err != nil {
+		t.Errorf("Could not make provider: %s", err)
+		return
+	}
+
+	res, err := e.GetCarbonIntensity(context.Background(), "FR")
+	if err != nil {
+		t.Fatalf("got error on GetCarbonIntensity: %s", err)
+	}
+
+	expected := []CarbonIntensity{
+		{
+			EmissionsType: "average",
+			MetricType:    "absolute",
+			Provider:      "RTE",
+			Location:      "FR",
+			Units:         "gCO2e per kWh",
+			ValidFrom:     time.Date(2024, 6, 1, 10, 15, 0, 0, time.UTC),
+			ValidTo:       time.Date(2024, 6, 1, 10, 30, 0, 0, time.UTC),
+			Value:         22,
+			IsEstimated:   false,
+		},
+		{
+			EmissionsType: "average",
+			MetricType:    "absolute",
+			Provider:      "RTE",
+			Location:      "FR",
+			Units:         "gCO2e per kWh",
+			ValidFrom:     time.Date(2024, 6, 1, 10, 30, 0, 0, time.UTC),
+			ValidTo:       time.Date(2024, 6, 1, 10, 45, 0, 0, time.UTC),
+			Value:         24,
+			IsEstimated:   true,
+		},
+		{
+			EmissionsType: "average",
+			MetricType:    "absolute",
+			Provider:      "RTE",
+			Location:      "FR",
+			Units:         "gCO2e per kWh",
+			ValidFrom:     time.Date(2024, 6, 1, 10, 45, 0, 0, time.UTC),
+			ValidTo:       time.Date(2024, 6, 1, 11, 0, 0, 0, time.UTC),
+			Value:         25.5,
+			IsEstimated:   true,
+		},
+	}
+	if !reflect.DeepEqual(expected, res) {
+		t.Errorf("want matching \n %s", cmp.Diff(res, expected))
+	}
+}
+
+func Test_RTE_DisableForecast(t *testing.T) {
+	ts := makeRTETestServer(t, "")
+	defer ts.Close()
+
+	c := RTEConfig{
+		APIURL:          ts.URL,
+		DisableForecast: true,
+	}
+	e, err := NewRTE(c)
+	if err != nil {
+		t.Errorf("Could not make provider: %s", err)
+		return
+	}
+
+	res, err := e.GetCarbonIntensity(context.Background(), "FR")
+	if err != nil {
+		t.Fatalf("got error on GetCarbonIntensity: %s", err)
+	}
+
+	// No forecast is requested when it is disabled.
+	expected := []CarbonIntensity{
+		{
+			EmissionsType: "average",
+			MetricType:    "absolute",
+			Provider:      "RTE",
+			Location:      "FR",
+			Units:         "gCO2e per kWh",
+			ValidFrom:     time.Date(2024, 6, 1, 10, 15, 0, 0, time.UTC),
+			ValidTo:       time.Date(2024, 6, 1, 10, 30, 0, 0, time.UTC),
+			Value:         22,
+			IsEstimated:   false,
+		},
+	}
+	if !reflect.DeepEqual(expected, res) {
+		t.Errorf("want matching \n %s", cmp.Diff(res, expected))
+	}
+}
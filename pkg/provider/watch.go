@@ -0,0 +1,142 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+// WatchOptions configures how often Watch polls the provider.
+type WatchOptions struct {
+	// MinInterval is the minimum wait between polls. It is also the wait
+	// when no result is valid in the future and after an error. The wait is
+	// doubled for each consecutive error. Defaults to 1 minute.
+	MinInterval time.Duration
+	// MaxInterval is the maximum wait between polls. Defaults to 1 hour.
+	MaxInterval time.Duration
+	// Delay is added to the valid to time of the current result as providers
+	// take time to publish the next value. Defaults to 1 minute.
+	Delay time.Duration
+}
+
+// Update is sent by Watch when the data for a location changes or getting it
+// fails.
+type Update struct {
+	Location string
+	Data     []CarbonIntensity
+	// Err is set if getting the data failed. Data is nil.
+	Err error
+}
+
+// Watch polls the client for the location and sends an update when the data
+// changes. Polls are scheduled for when the current result is no longer
+// valid. Errors are also sent as updates. The channel is closed when the
+// context is cancelled.
+func Watch(ctx context.Context, client Interface, location string, opts WatchOptions) <-chan Update {
+	if opts.MinInterval <= 0 {
+		opts.MinInterval = time.Minute
+	}
+	if opts.MaxInterval <= 0 {
+		opts.MaxInterval = time.Hour
+	}
+	if opts.MaxInterval < opts.MinInterval {
+		opts.MaxInterval = opts.MinInterval
+	}
+	if opts.Delay <= 0 {
+		opts.Delay = time.Minute
+	}
+
+	ch := make(chan Update)
+
+	go func() {
+		defer close(ch)
+
+		var last []CarbonIntensity
+		errWait := opts.MinInterval
+
+		for {
+			data, err := client.GetCarbonIntensity(ctx, location)
+			if ctx.Err() != nil {
+				return
+			}
+
+			var wait time.Duration
+			var update *Update
+
+			if err != nil {
+				update = &Update{
+					Location: location,
+					Err:      err,
+				}
+				wait = errWait
+				errWait = minDuration(2*errWait, opts.MaxInterval)
+			} else {
+				errWait = opts.MinInterval
+				wait = nextPoll(time.Now(), data, opts)
+				if last == nil || !reflect.DeepEqual(last, data) {
+					update = &Update{
+						Location: location,
+						Data:     data,
+					}
+				}
+				last = data
+			}
+
+			if update != nil {
+				select {
+				case ch <- *update:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+	}()
+
+	return ch
+}
+
+// nextPoll returns the wait until the earliest valid to time after now of the
+// results that are currently valid plus the delay. It is limited to the min
+// and max intervals.
+func nextPoll(now time.Time, data []CarbonIntensity, opts WatchOptions) time.Duration {
+	var next time.Time
+
+	for _, point := range data {
+		if point.ValidFrom.After(now) || !point.ValidTo.After(now) {
+			// Skip forecasts and results that have expired.
+			continue
+		}
+		if next.IsZero() || point.ValidTo.Before(next) {
+			next = point.ValidTo
+		}
+	}
+	if next.IsZero() {
+		return opts.MinInterval
+	}
+
+	wait := next.Add(opts.Delay).Sub(now)
+	if wait < opts.MinInterval {
+		return opts.MinInterval
+	}
+	if wait > opts.MaxInterval {
+		return opts.MaxInterval
+	}
+
+	return wait
+}
+
+func minDuration(a, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+
+	return b
+}
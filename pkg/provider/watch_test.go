@@ -0,0 +1,166 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeWatchProvider returns each response in turn and then repeats the last.
+type fakeWatchProvider struct {
+	mu        sync.Mutex
+	responses []fakeWatchResponse
+	calls     int
+}
+
+type fakeWatchResponse struct {
+	value float64
+	err   error
+}
+
+func (f *fakeWatchProvider) GetCarbonIntensity(ctx context.Context, location string) ([]CarbonIntensity, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	response := f.responses[len(f.responses)-1]
+	if f.calls < len(f.responses) {
+		response = f.responses[f.calls]
+	}
+	f.calls++
+
+	if response.err != nil {
+		return nil, response.err
+	}
+
+	return []CarbonIntensity{
+		{
+			EmissionsType: AverageEmissionsType,
+			MetricType:    AbsoluteMetricType,
+			Location:      location,
+			Units:         GramsCO2EPerkWh,
+			ValidFrom:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			ValidTo:       time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC),
+			Value:         response.value,
+		},
+	}, nil
+}
+
+func Test_Watch(t *testing.T) {
+	errUpstream := errors.New("upstream")
+
+	p := &fakeWatchProvider{
+		responses: []fakeWatchResponse{
+			{value: 100},
+			{value: 100},
+			{err: errUpstream},
+			{value: 100},
+			{value: 200},
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	updates := Watch(ctx, p, "UK", WatchOptions{
+		MinInterval: time.Millisecond,
+		Delay:       time.Millisecond,
+	})
+
+	expected := []struct {
+		value float64
+		err   error
+	}{
+		{value: 100},
+		// The unchanged value is skipped.
+		{err: errUpstream},
+		// The value after an error is only sent if it changed.
+		{value: 200},
+	}
+
+	for i, want := range expected {
+		select {
+		case update := <-updates:
+			if update.Location != "UK" {
+				t.Errorf("%d: expected location UK got %q", i, update.Location)
+			}
+			if want.err != nil {
+				if !errors.Is(update.Err, want.err) {
+					t.Errorf("%d: expected error %v got %v", i, want.err, update.Err)
+				}
+				continue
+			}
+			if update.Err != nil || len(update.Data) != 1 || update.Data[0].Value != want.value {
+				t.Errorf("%d: expected value %v got %v %v", i, want.value, update.Data, update.Err)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%d: timed out waiting for update", i)
+		}
+	}
+
+	cancel()
+	for range updates {
+		// Drain until the channel is closed.
+	}
+}
+
+func Test_Watch_NextPoll(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 10, 0, 0, time.UTC)
+	opts := WatchOptions{
+		MinInterval: time.Minute,
+		MaxInterval: time.Hour,
+		Delay:       2 * time.Minute,
+	}
+
+	tests := []struct {
+		name     string
+		data     []CarbonIntensity
+		expected time.Duration
+	}{
+		{
+			name: "current value",
+			data: []CarbonIntensity{
+				{
+					ValidFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+					ValidTo:   time.Date(2024, 1, 1, 0, 30, 0, 0, time.UTC),
+				},
+				{
+					// Forecasts are ignored.
+					ValidFrom: time.Date(2024, 1, 1, 0, 30, 0, 0, time.UTC),
+					ValidTo:   time.Date(2024, 1, 1, 0, 31, 0, 0, time.UTC),
+				},
+			},
+			expected: 22 * time.Minute,
+		},
+		{
+			name: "expired value",
+			data: []CarbonIntensity{
+				{
+					ValidFrom: time.Date(2023, 12, 31, 23, 0, 0, 0, time.UTC),
+					ValidTo:   time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+				},
+			},
+			expected: time.Minute,
+		},
+		{
+			name: "yearly value",
+			data: []CarbonIntensity{
+				{
+					ValidFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+					ValidTo:   time.Date(2024, 12, 31, 23, 59, 0, 0, time.UTC),
+				},
+			},
+			expected: time.Hour,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			wait := nextPoll(now, tc.data, opts)
+			if wait != tc.expected {
+				t.Errorf("expected %s got %s", tc.expected, wait)
+			}
+		})
+	}
+}
@@ -6,27 +6,51 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"strconv"
+	"sync"
 	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// wattTimeTokenTTL is how long WattTime login tokens are valid for.
+	wattTimeTokenTTL = 30 * time.Minute
+	// wattTimeTokenRefresh is how long before expiry the token is refreshed.
+	wattTimeTokenRefresh = 5 * time.Minute
 )
 
 type WattTimeClient struct {
+	*httpClient
+
 	cache       *cacheStore
-	client      *http.Client
 	apiURL      string
 	apiUser     string
 	apiPassword string
+	cacheToken  bool
+
+	// logins ensures only one login request is made at a time.
+	logins      singleflight.Group
+	mu          sync.Mutex
 	token       string
+	tokenExpiry time.Time
 }
 
 type WattTimeConfig struct {
 	Client      *http.Client
+	Retry       RetryConfig
+	RateLimit   RateLimitConfig
+	Logger      *slog.Logger
 	APIURL      string
 	APIUser     string
 	APIPassword string
 	CacheFile   string
+	// CacheToken saves the login token in the cache file so it is reused
+	// until it expires e.g. by short lived CLI runs. The cache file is made
+	// readable only by the current user. Requires CacheFile to be set.
+	CacheToken bool
 }
 
 func NewWattTime(config WattTimeConfig) (Interface, error) {
@@ -49,22 +73,18 @@ func NewWattTime(config WattTimeConfig) (Interface, error) {
 
 	w := &WattTimeClient{
 		cache:       cache,
-		client:      config.Client,
+		httpClient:  newHTTPClient(config.Client, config.Retry, config.RateLimit, newLogger(config.Logger, WattTime)),
 		apiURL:      config.APIURL,
 		apiUser:     config.APIUser,
 		apiPassword: config.APIPassword,
+		cacheToken:  config.CacheToken && config.CacheFile != "",
 	}
 
 	return w, nil
 }
 
 func (w *WattTimeClient) GetCarbonIntensity(ctx context.Context, location string) ([]CarbonIntensity, error) {
-	result, err := w.fetchCarbonIntensityData(ctx, location)
-	if err != nil {
-		return nil, err
-	}
-
-	return result, nil
+	return w.coalesce(ctx, WattTime, location, w.fetchCarbonIntensityData)
 }
 
 func (w *WattTimeClient) fetchCarbonIntensityData(ctx context.Context, location string) ([]CarbonIntensity, error) {
@@ -76,23 +96,20 @@ func (w *WattTimeClient) fetchCarbonIntensityData(ctx context.Context, location
 		return result, nil
 	}
 
-	if w.token == "" {
-		token, err := w.getAccessToken(ctx)
-		if err != nil {
-			return nil, err
-		}
-		w.token = token
+	token, err := w.getToken(ctx, "")
+	if err != nil {
+		return nil, err
 	}
 
-	indexData, err := w.getCarbonIntensityData(ctx, location)
+	indexData, err := w.getCarbonIntensityData(ctx, location, token)
 	if errors.Is(err, ErrReceived403Forbidden) {
-		token, err := w.getAccessToken(ctx)
+		// The token may have been revoked so login again.
+		token, err = w.getToken(ctx, token)
 		if err != nil {
 			return nil, err
 		}
-		w.token = token
 
-		indexData, err = w.getCarbonIntensityData(ctx, location)
+		indexData, err = w.getCarbonIntensityData(ctx, location, token)
 		if err != nil {
 			return nil, err
 		}
@@ -113,6 +130,63 @@ func (w *WattTimeClient) fetchCarbonIntensityData(ctx context.Context, location
 	return result, nil
 }
 
+// getToken returns the current token unless it is close to expiry or equal to
+// invalid. Otherwise a new token is requested. Concurrent callers share a
+// single login request.
+func (w *WattTimeClient) getToken(ctx context.Context, invalid string) (string, error) {
+	w.mu.Lock()
+	if w.token == "" && w.cacheToken {
+		token, expiry, err := w.cache.getCacheToken(ctx)
+		if err != nil {
+			w.logger.WarnContext(ctx, "could not load token from cache", "error", err)
+		}
+		w.token = token
+		w.tokenExpiry = expiry
+	}
+	if w.token != "" && w.token != invalid && time.Until(w.tokenExpiry) > wattTimeTokenRefresh {
+		token := w.token
+		w.mu.Unlock()
+		return token, nil
+	}
+	w.mu.Unlock()
+
+	// The login is not cancelled if the caller that started it is cancelled
+	// as other callers may be waiting for it.
+	loginCtx := context.WithoutCancel(ctx)
+
+	ch := w.logins.DoChan("login", func() (interface{}, error) {
+		token, err := w.getAccessToken(loginCtx)
+		if err != nil {
+			return "", err
+		}
+		expiry := time.Now().Add(wattTimeTokenTTL)
+
+		w.mu.Lock()
+		w.token = token
+		w.tokenExpiry = expiry
+		w.mu.Unlock()
+
+		if w.cacheToken {
+			err = w.cache.setCacheToken(loginCtx, token, expiry)
+			if err != nil {
+				w.logger.WarnContext(loginCtx, "could not save token to cache", "error", err)
+			}
+		}
+
+		return token, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return "", res.Err
+		}
+		return res.Val.(string), nil
+	}
+}
+
 func (w *WattTimeClient) getAccessToken(ctx context.Context) (string, error) {
 	loginURL, err := w.loginURL()
 	if err != nil {
@@ -125,9 +199,7 @@ func (w *WattTimeClient) getAccessToken(ctx context.Context) (string, error) {
 	}
 	req.SetBasicAuth(w.apiUser, w.apiPassword)
 
-	log.Printf("calling %s", req.URL)
-
-	resp, err := w.client.Do(req)
+	resp, err := w.do(req)
 	if err != nil {
 		return "", err
 	}
@@ -139,19 +211,22 @@ func (w *WattTimeClient) getAccessToken(ctx context.Context) (string, error) {
 
 	bytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", nil
+		return "", err
 	}
 
 	loginResp := wattTimeLoginResp{}
 	err = json.Unmarshal(bytes, &loginResp)
 	if err != nil {
-		return "", nil
+		return "", err
+	}
+	if loginResp.Token == "" {
+		return "", fmt.Errorf("login response has no token: %w", ErrNoResponse)
 	}
 
 	return loginResp.Token, nil
 }
 
-func (w *WattTimeClient) getCarbonIntensityData(ctx context.Context, location string) (*wattTimeIndexData, error) {
+func (w *WattTimeClient) getCarbonIntensityData(ctx context.Context, location, token string) (*wattTimeIndexData, error) {
 	indexURL, err := w.indexURL(location)
 	if err != nil {
 		return nil, err
@@ -162,11 +237,9 @@ func (w *WattTimeClient) getCarbonIntensityData(ctx context.Context, location st
 		return nil, err
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", w.token))
-
-	log.Printf("calling %s", req.URL)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 
-	resp, err := w.client.Do(req)
+	resp, err := w.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -5,7 +5,11 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"reflect"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -83,3 +87,162 @@ func Test_WattTime_SimpleRequest(t *testing.T) {
 		t.Errorf("want matching \n %s", cmp.Diff(result, expected))
 	}
 }
+
+// makeWattTimeTokenServer returns a server that issues numbered tokens and
+// only accepts the latest one. The number of logins is counted.
+func makeWattTimeTokenServer(t *testing.T, logins *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			n := atomic.AddInt32(logins, 1)
+			// Slow down logins so concurrent requests overlap.
+			time.Sleep(10 * time.Millisecond)
+			fmt.Fprintf(w, `{"token":"token-%d"}`, n)
+		case "/index":
+			if r.Header.Get("Authorization") != fmt.Sprintf("Bearer token-%d", atomic.LoadInt32(logins)) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			fmt.Fprintln(w, MockWattTimeIndexResponse)
+		default:
+			t.Errorf("unknown path %#q", r.URL.Path)
+		}
+	}))
+}
+
+func Test_WattTime_ConcurrentLogin(t *testing.T) {
+	var logins int32
+
+	ts := makeWattTimeTokenServer(t, &logins)
+	defer ts.Close()
+
+	c := WattTimeConfig{
+		APIURL:      ts.URL,
+		APIUser:     "user",
+		APIPassword: "password",
+	}
+	w, err := NewWattTime(c)
+	if err != nil {
+		t.Fatalf("Could not make provider: %s", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := w.GetCarbonIntensity(context.Background(), fmt.Sprintf("BA_%d", i))
+			if err != nil {
+				t.Errorf("Got error on GetCarbonIntensity: %s", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&logins); n != 1 {
+		t.Errorf("expected 1 login got %d", n)
+	}
+}
+
+func Test_WattTime_TokenRefresh(t *testing.T) {
+	var logins int32
+
+	ts := makeWattTimeTokenServer(t, &logins)
+	defer ts.Close()
+
+	c := WattTimeConfig{
+		APIURL:      ts.URL,
+		APIUser:     "user",
+		APIPassword: "password",
+	}
+	p, err := NewWattTime(c)
+	if err != nil {
+		t.Fatalf("Could not make provider: %s", err)
+	}
+	w := p.(*WattTimeClient)
+
+	_, err = w.GetCarbonIntensity(context.Background(), "BA_1")
+	if err != nil {
+		t.Fatalf("Got error on GetCarbonIntensity: %s", err)
+	}
+
+	// The token is refreshed before it expires.
+	w.mu.Lock()
+	w.tokenExpiry = time.Now().Add(time.Minute)
+	w.mu.Unlock()
+
+	_, err = w.GetCarbonIntensity(context.Background(), "BA_2")
+	if err != nil {
+		t.Fatalf("Got error on GetCarbonIntensity: %s", err)
+	}
+	if n := atomic.LoadInt32(&logins); n != 2 {
+		t.Errorf("expected 2 logins got %d", n)
+	}
+
+	// A revoked token is replaced after a 403.
+	atomic.AddInt32(&logins, 1)
+
+	_, err = w.GetCarbonIntensity(context.Background(), "BA_3")
+	if err != nil {
+		t.Fatalf("Got error on GetCarbonIntensity: %s", err)
+	}
+	if n := atomic.LoadInt32(&logins); n != 4 {
+		t.Errorf("expected 4 logins got %d", n)
+	}
+}
+
+func Test_WattTime_CacheToken(t *testing.T) {
+	var logins int32
+
+	ts := makeWattTimeTokenServer(t, &logins)
+	defer ts.Close()
+
+	cacheDir := t.TempDir()
+	cacheFile := filepath.Join(cacheDir, "watttime.org.json")
+
+	// A cache file written by an older version is readable by others.
+	err := os.WriteFile(cacheFile, []byte("{}"), 0644)
+	if err != nil {
+		t.Fatalf("could not write cache file: %s", err)
+	}
+
+	for i := 1; i <= 2; i++ {
+		c := WattTimeConfig{
+			APIURL:      ts.URL,
+			APIUser:     "user",
+			APIPassword: "password",
+			CacheFile:   cacheFile,
+			CacheToken:  true,
+		}
+		w, err := NewWattTime(c)
+		if err != nil {
+			t.Fatalf("Could not make provider: %s", err)
+		}
+
+		_, err = w.GetCarbonIntensity(context.Background(), fmt.Sprintf("BA_%d", i))
+		if err != nil {
+			t.Fatalf("Got error on GetCarbonIntensity: %s", err)
+		}
+	}
+
+	if n := atomic.LoadInt32(&logins); n != 1 {
+		t.Errorf("expected 1 login got %d", n)
+	}
+
+	info, err := os.Stat(cacheFile)
+	if err != nil {
+		t.Fatalf("could not stat cache file: %s", err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("expected cache file mode 0600 got %o", mode)
+	}
+
+	// Only the cache file and its lock file are left in the directory.
+	entries, err := os.ReadDir(cacheDir)
+	if err != nil {
+		t.Fatalf("could not read cache dir: %s", err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("expected cache and lock files got %d files", len(entries))
+	}
+}
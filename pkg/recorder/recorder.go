@@ -0,0 +1,324 @@
+// Package recorder records HTTP exchanges with provider APIs to a directory
+// and replays them. This allows issues to be reproduced and demos to be run
+// without network access. Secrets such as auth headers and tokens are
+// redacted before exchanges are saved.
+package recorder
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces secrets in recorded exchanges.
+const Redacted = "REDACTED"
+
+var (
+	// sensitiveHeaders are removed from recorded requests and responses.
+	sensitiveHeaders = []string{
+		"Authorization",
+		"Auth-Token",
+		"Cookie",
+		"Proxy-Authorization",
+		"Set-Cookie",
+		"X-Api-Key",
+	}
+
+	// sensitiveNames are query params and JSON fields containing secrets.
+	// Names are compared case insensitively.
+	sensitiveNames = []string{
+		"access_token",
+		"api_key",
+		"apikey",
+		"key",
+		"password",
+		"securitytoken",
+		"token",
+	}
+
+	// jsonSecretPattern matches JSON string fields that contain secrets.
+	jsonSecretPattern = regexp.MustCompile(`(?i)("(?:` + strings.Join(sensitiveNames, "|") + `)"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+
+	// fileNamePattern matches chars that are replaced in file names.
+	fileNamePattern = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+)
+
+// Exchange is a recorded request and response.
+type Exchange struct {
+	Method     string      `json:"method"`
+	URL        string      `json:"url"`
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       string      `json:"body"`
+}
+
+// Recorder is an http.RoundTripper that saves each exchange to a directory.
+// Exchanges for the same request are appended to the same file.
+type Recorder struct {
+	dir       string
+	transport http.RoundTripper
+
+	mu sync.Mutex
+}
+
+// NewRecorder returns a recorder that saves exchanges to dir. If transport
+// is nil http.DefaultTransport is used.
+func NewRecorder(dir string, transport http.RoundTripper) (*Recorder, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	return &Recorder{
+		dir:       dir,
+		transport: transport,
+	}, nil
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	exchange := Exchange{
+		Method:     req.Method,
+		URL:        redactURL(req.URL),
+		StatusCode: resp.StatusCode,
+		Header:     redactHeader(resp.Header),
+		Body:       redactBody(string(body)),
+	}
+
+	err = r.save(exchange)
+	if err != nil {
+		return nil, fmt.Errorf("could not record %s: %w", exchange.URL, err)
+	}
+
+	// The unredacted response is returned so the client works as normal.
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	return resp, nil
+}
+
+func (r *Recorder) save(exchange Exchange) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	path := filepath.Join(r.dir, fileName(exchange.Method, exchange.URL))
+
+	exchanges, err := readExchanges(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	exchanges = append(exchanges, exchange)
+
+	data, err := json.MarshalIndent(exchanges, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Replayer is an http.RoundTripper that returns exchanges saved by a
+// Recorder. Requests are matched by method and URL with secrets redacted. If
+// there is no exact match the method and path are used so requests with time
+// based query params can still be replayed. When a request was recorded more
+// than once the exchanges are returned in order and the last one is repeated.
+type Replayer struct {
+	mu        sync.Mutex
+	exchanges map[string][]Exchange
+	byPath    map[string][]Exchange
+	played    map[string]int
+}
+
+// NewReplayer loads the exchanges saved in dir.
+func NewReplayer(dir string) (*Replayer, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no recorded exchanges found in %s", dir)
+	}
+	sort.Strings(files)
+
+	r := &Replayer{
+		exchanges: map[string][]Exchange{},
+		byPath:    map[string][]Exchange{},
+		played:    map[string]int{},
+	}
+
+	for _, file := range files {
+		exchanges, err := readExchanges(file)
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s: %w", file, err)
+		}
+		for _, exchange := range exchanges {
+			u, err := url.Parse(exchange.URL)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse url in %s: %w", file, err)
+			}
+			key := exchange.Method + " " + exchange.URL
+			pathKey := exchange.Method + " " + pathURL(u)
+
+			r.exchanges[key] = append(r.exchanges[key], exchange)
+			r.byPath[pathKey] = append(r.byPath[pathKey], exchange)
+		}
+	}
+
+	return r, nil
+}
+
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	key := req.Method + " " + redactURL(req.URL)
+	exchanges := r.exchanges
+	if _, ok := exchanges[key]; !ok {
+		key = req.Method + " " + pathURL(req.URL)
+		exchanges = r.byPath
+	}
+
+	r.mu.Lock()
+	recorded, ok := exchanges[key]
+	if !ok {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("no recorded exchange for %s %s", req.Method, redactURL(req.URL))
+	}
+	i := r.played[key]
+	if i < len(recorded)-1 {
+		r.played[key] = i + 1
+	}
+	exchange := recorded[i]
+	r.mu.Unlock()
+
+	header := exchange.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", exchange.StatusCode, http.StatusText(exchange.StatusCode)),
+		StatusCode:    exchange.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(exchange.Body)),
+		ContentLength: int64(len(exchange.Body)),
+		Request:       req,
+	}, nil
+}
+
+func readExchanges(path string) ([]Exchange, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var exchanges []Exchange
+	err = json.Unmarshal(data, &exchanges)
+	if err != nil {
+		return nil, err
+	}
+
+	return exchanges, nil
+}
+
+// fileName returns a readable file name for the request that is unique
+// because it includes a hash of the method and URL.
+func fileName(method, rawURL string) string {
+	hash := sha256.Sum256([]byte(method + " " + rawURL))
+
+	name := rawURL
+	if u, err := url.Parse(rawURL); err == nil {
+		name = u.Host + u.Path
+	}
+	name = fileNamePattern.ReplaceAllString(name, "_")
+	if len(name) > 80 {
+		name = name[:80]
+	}
+
+	return fmt.Sprintf("%s_%s.json", strings.Trim(name, "_"), hex.EncodeToString(hash[:4]))
+}
+
+func isSensitive(name string) bool {
+	for _, sensitive := range sensitiveNames {
+		if strings.EqualFold(name, sensitive) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// redactURL returns the URL with secrets in query params and user info
+// redacted. Query params are sorted so the URL can be used for matching.
+func redactURL(u *url.URL) string {
+	redacted := *u
+	if redacted.User != nil {
+		redacted.User = url.User(Redacted)
+	}
+
+	query := redacted.Query()
+	for name := range query {
+		if isSensitive(name) {
+			query.Set(name, Redacted)
+		}
+	}
+	redacted.RawQuery = query.Encode()
+
+	return redacted.String()
+}
+
+// pathURL returns the URL without the query.
+func pathURL(u *url.URL) string {
+	redacted := *u
+	redacted.User = nil
+	redacted.RawQuery = ""
+
+	return redacted.String()
+}
+
+func redactHeader(header http.Header) http.Header {
+	redacted := header.Clone()
+	// The body may be decompressed so the length is set when replaying.
+	redacted.Del("Content-Length")
+	for _, name := range sensitiveHeaders {
+		if redacted.Get(name) != "" {
+			redacted.Set(name, Redacted)
+		}
+	}
+
+	return redacted
+}
+
+// redactBody replaces the values of JSON fields that contain secrets such as
+// the token returned by the WattTime login endpoint.
+func redactBody(body string) string {
+	return jsonSecretPattern.ReplaceAllString(body, `$1"`+Redacted+`"`)
+}
@@ -0,0 +1,199 @@
+package series
+
+import (
+	"sort"
+	"time"
+
+	"github.com/thegreenwebfoundation/grid-intensity-go/pkg/provider"
+)
+
+// Aggregation is how the values in an interval are combined by Resample.
+type Aggregation int
+
+const (
+	// Mean is the mean of the values valid from a time in the interval.
+	Mean Aggregation = iota
+	// TimeWeighted is the mean of the values weighted by how long they are
+	// valid for during the interval. Data points without a valid to time
+	// after their valid from time are skipped.
+	TimeWeighted
+)
+
+// Interpolation is how the values for missing intervals are set by FillGaps.
+type Interpolation int
+
+const (
+	// Previous repeats the value before the gap.
+	Previous Interpolation = iota
+	// Linear interpolates between the values before and after the gap.
+	Linear
+)
+
+// seriesKey identifies data points that can be combined.
+type seriesKey struct {
+	provider      string
+	location      string
+	emissionsType string
+	metricType    string
+	units         string
+}
+
+func keyOf(point provider.CarbonIntensity) seriesKey {
+	return seriesKey{
+		provider:      point.Provider,
+		location:      point.Location,
+		emissionsType: point.EmissionsType,
+		metricType:    point.MetricType,
+		units:         point.Units,
+	}
+}
+
+// split returns the data points for each key in the order the keys first
+// appear in the series.
+func (s Series) split() []Series {
+	index := map[seriesKey]int{}
+	var result []Series
+
+	for _, point := range s {
+		key := keyOf(point)
+		i, ok := index[key]
+		if !ok {
+			i = len(result)
+			index[key] = i
+			result = append(result, nil)
+		}
+		result[i] = append(result[i], point)
+	}
+
+	return result
+}
+
+type bucket struct {
+	sum       float64
+	weight    float64
+	estimated bool
+}
+
+// Resample returns a data point for each interval that has data. Intervals
+// are truncated to multiples of the interval since the zero time so hourly
+// intervals start on the hour. Data is resampled separately for each
+// provider, location, emissions type, metric type and units. A resampled
+// value is estimated if any of the values used are estimated, so filter
+// estimated or actual values first if both are present. The series is
+// returned unchanged if the interval is not positive.
+func (s Series) Resample(interval time.Duration, aggregation Aggregation) Series {
+	if interval <= 0 {
+		return s
+	}
+
+	var result Series
+
+	for _, group := range s.split() {
+		buckets := map[time.Time]*bucket{}
+		add := func(start time.Time, value, weight float64, estimated bool) {
+			b, ok := buckets[start]
+			if !ok {
+				b = &bucket{}
+				buckets[start] = b
+			}
+			b.sum += value * weight
+			b.weight += weight
+			b.estimated = b.estimated || estimated
+		}
+
+		for _, point := range group {
+			validFrom := point.ValidFrom.UTC()
+
+			switch aggregation {
+			case Mean:
+				add(validFrom.Truncate(interval), point.Value, 1, point.IsEstimated)
+			case TimeWeighted:
+				validTo := point.ValidTo.UTC()
+				for start := validFrom.Truncate(interval); start.Before(validTo); start = start.Add(interval) {
+					from := maxTime(start, validFrom)
+					to := minTime(start.Add(interval), validTo)
+					if overlap := to.Sub(from); overlap > 0 {
+						add(start, point.Value, overlap.Seconds(), point.IsEstimated)
+					}
+				}
+			}
+		}
+
+		for start, b := range buckets {
+			if b.weight == 0 {
+				continue
+			}
+
+			point := group[0]
+			point.ValidFrom = start
+			point.ValidTo = start.Add(interval)
+			point.Value = b.sum / b.weight
+			point.IsEstimated = b.estimated
+			result = append(result, point)
+		}
+	}
+
+	result.Sort()
+
+	return result
+}
+
+// FillGaps adds data points for missing intervals between the data points of
+// a series with a regular interval, e.g. after Resample. Gaps are filled
+// separately for each provider, location, emissions type, metric type and
+// units. Added data points are estimated. The series is returned unchanged if
+// the interval is not positive.
+func (s Series) FillGaps(interval time.Duration, interpolation Interpolation) Series {
+	if interval <= 0 {
+		return s
+	}
+
+	var result Series
+
+	for _, group := range s.split() {
+		sort.SliceStable(group, func(i, j int) bool {
+			return group[i].ValidFrom.Before(group[j].ValidFrom)
+		})
+
+		for i, point := range group {
+			result = append(result, point)
+			if i == len(group)-1 {
+				continue
+			}
+
+			next := group[i+1]
+			gap := next.ValidFrom.Sub(point.ValidFrom)
+
+			for t := point.ValidFrom.Add(interval); t.Before(next.ValidFrom); t = t.Add(interval) {
+				filled := point
+				filled.ValidFrom = t
+				filled.ValidTo = t.Add(interval)
+				filled.IsEstimated = true
+				if interpolation == Linear {
+					filled.Value = point.Value + (next.Value-point.Value)*float64(t.Sub(point.ValidFrom))/float64(gap)
+				}
+				result = append(result, filled)
+			}
+		}
+	}
+
+	result.Sort()
+
+	return result
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+
+	return b
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,131 @@
+package series
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/thegreenwebfoundation/grid-intensity-go/pkg/provider"
+)
+
+func halfHour(validFrom time.Time, value float64, estimated bool) provider.CarbonIntensity {
+	return provider.CarbonIntensity{
+		EmissionsType: provider.AverageEmissionsType,
+		MetricType:    provider.AbsoluteMetricType,
+		Provider:      provider.CarbonIntensityOrgUK,
+		Location:      "UK",
+		Units:         provider.GramsCO2EPerkWh,
+		ValidFrom:     validFrom,
+		ValidTo:       validFrom.Add(30 * time.Minute),
+		Value:         value,
+		IsEstimated:   estimated,
+	}
+}
+
+func Test_Series_Resample(t *testing.T) {
+	hour := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	s := Series{
+		halfHour(hour, 100, false),
+		halfHour(hour.Add(30*time.Minute), 200, true),
+		halfHour(hour.Add(time.Hour), 300, false),
+	}
+	// A 5 minute value within the first half hour.
+	short := halfHour(hour.Add(10*time.Minute), 400, false)
+	short.ValidTo = short.ValidFrom.Add(5 * time.Minute)
+	s = append(s, short)
+
+	tests := []struct {
+		name        string
+		aggregation Aggregation
+		values      []float64
+	}{
+		{
+			name:        "mean",
+			aggregation: Mean,
+			values:      []float64{(100 + 200 + 400) / 3.0, 300},
+		},
+		{
+			name:        "time weighted",
+			aggregation: TimeWeighted,
+			values:      []float64{(100*30 + 200*30 + 400*5) / 65.0, 300},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result := s.Resample(time.Hour, tc.aggregation)
+
+			expected := Series{
+				{
+					EmissionsType: provider.AverageEmissionsType,
+					MetricType:    provider.AbsoluteMetricType,
+					Provider:      provider.CarbonIntensityOrgUK,
+					Location:      "UK",
+					Units:         provider.GramsCO2EPerkWh,
+					ValidFrom:     hour,
+					ValidTo:       hour.Add(time.Hour),
+					Value:         tc.values[0],
+					IsEstimated:   true,
+				},
+				{
+					EmissionsType: provider.AverageEmissionsType,
+					MetricType:    provider.AbsoluteMetricType,
+					Provider:      provider.CarbonIntensityOrgUK,
+					Location:      "UK",
+					Units:         provider.GramsCO2EPerkWh,
+					ValidFrom:     hour.Add(time.Hour),
+					ValidTo:       hour.Add(2 * time.Hour),
+					Value:         tc.values[1],
+				},
+			}
+			if !reflect.DeepEqual(expected, result) {
+				t.Errorf("want matching \n %s", cmp.Diff(result, expected))
+			}
+		})
+	}
+}
+
+func Test_Series_FillGaps(t *testing.T) {
+	hour := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	s := Series{
+		halfHour(hour.Add(90*time.Minute), 400, false),
+		halfHour(hour, 100, false),
+	}
+
+	tests := []struct {
+		name          string
+		interpolation Interpolation
+		values        []float64
+	}{
+		{
+			name:          "previous",
+			interpolation: Previous,
+			values:        []float64{100, 100, 100, 400},
+		},
+		{
+			name:          "linear",
+			interpolation: Linear,
+			values:        []float64{100, 200, 300, 400},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result := s.FillGaps(30*time.Minute, tc.interpolation)
+
+			expected := Series{
+				halfHour(hour, tc.values[0], false),
+				halfHour(hour.Add(30*time.Minute), tc.values[1], true),
+				halfHour(hour.Add(time.Hour), tc.values[2], true),
+				halfHour(hour.Add(90*time.Minute), tc.values[3], false),
+			}
+			if !reflect.DeepEqual(expected, result) {
+				t.Errorf("want matching \n %s", cmp.Diff(result, expected))
+			}
+		})
+	}
+}
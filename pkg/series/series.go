@@ -0,0 +1,205 @@
+// Package series has utilities for working with carbon intensity data
+// returned by providers. Data can be filtered, sorted, resampled to a fixed
+// interval, have gaps filled and be aligned across locations or providers so
+// values can be compared.
+package series
+
+import (
+	"sort"
+	"time"
+
+	"github.com/thegreenwebfoundation/grid-intensity-go/pkg/provider"
+)
+
+// Series is carbon intensity data for one or more locations, providers or
+// types of data.
+type Series []provider.CarbonIntensity
+
+// Predicate reports whether a data point should be kept by Filter.
+type Predicate func(point provider.CarbonIntensity) bool
+
+// Filter returns the data points that match all the predicates.
+func (s Series) Filter(predicates ...Predicate) Series {
+	var result Series
+
+	for _, point := range s {
+		keep := true
+		for _, predicate := range predicates {
+			if !predicate(point) {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			result = append(result, point)
+		}
+	}
+
+	return result
+}
+
+// WithLocation matches data points for the location.
+func WithLocation(location string) Predicate {
+	return func(point provider.CarbonIntensity) bool {
+		return point.Location == location
+	}
+}
+
+// WithProvider matches data points from the provider.
+func WithProvider(providerName string) Predicate {
+	return func(point provider.CarbonIntensity) bool {
+		return point.Provider == providerName
+	}
+}
+
+// WithEmissionsType matches data points with the emissions type.
+func WithEmissionsType(emissionsType string) Predicate {
+	return func(point provider.CarbonIntensity) bool {
+		return point.EmissionsType == emissionsType
+	}
+}
+
+// WithMetricType matches data points with the metric type.
+func WithMetricType(metricType string) Predicate {
+	return func(point provider.CarbonIntensity) bool {
+		return point.MetricType == metricType
+	}
+}
+
+// WithEstimated matches estimated data points if estimated is true and actual
+// data points if it is false.
+func WithEstimated(estimated bool) Predicate {
+	return func(point provider.CarbonIntensity) bool {
+		return point.IsEstimated == estimated
+	}
+}
+
+// Between matches data points valid from a time in the range from inclusive
+// to exclusive.
+func Between(from, to time.Time) Predicate {
+	return func(point provider.CarbonIntensity) bool {
+		return !point.ValidFrom.Before(from) && point.ValidFrom.Before(to)
+	}
+}
+
+// Sort sorts the data points by valid from time. Data points for the same time
+// are sorted by provider, location, emissions type, metric type and then
+// actual values before estimated values.
+func (s Series) Sort() {
+	sort.SliceStable(s, func(i, j int) bool {
+		a, b := s[i], s[j]
+		if !a.ValidFrom.Equal(b.ValidFrom) {
+			return a.ValidFrom.Before(b.ValidFrom)
+		}
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		if a.Location != b.Location {
+			return a.Location < b.Location
+		}
+		if a.EmissionsType != b.EmissionsType {
+			return a.EmissionsType < b.EmissionsType
+		}
+		if a.MetricType != b.MetricType {
+			return a.MetricType < b.MetricType
+		}
+		return !a.IsEstimated && b.IsEstimated
+	})
+}
+
+// Latest returns the data point with the latest valid from time. If there
+// are several the first is returned. False is returned if the series is
+// empty.
+func (s Series) Latest() (provider.CarbonIntensity, bool) {
+	if len(s) == 0 {
+		return provider.CarbonIntensity{}, false
+	}
+
+	latest := s[0]
+	for _, point := range s[1:] {
+		if point.ValidFrom.After(latest.ValidFrom) {
+			latest = point
+		}
+	}
+
+	return latest, true
+}
+
+// ByLocation splits the series by location.
+func (s Series) ByLocation() map[string]Series {
+	return s.groupBy(func(point provider.CarbonIntensity) string {
+		return point.Location
+	})
+}
+
+// ByProvider splits the series by provider.
+func (s Series) ByProvider() map[string]Series {
+	return s.groupBy(func(point provider.CarbonIntensity) string {
+		return point.Provider
+	})
+}
+
+func (s Series) groupBy(key func(provider.CarbonIntensity) string) map[string]Series {
+	result := map[string]Series{}
+	for _, point := range s {
+		k := key(point)
+		result[k] = append(result[k], point)
+	}
+
+	return result
+}
+
+// Row is the data points of aligned series that are valid from the same
+// time. Points has an entry for each series which is nil if the series has no
+// data point for the time.
+type Row struct {
+	ValidFrom time.Time
+	Points    []*provider.CarbonIntensity
+}
+
+// Complete reports whether every series has a data point for the row.
+func (r Row) Complete() bool {
+	for _, point := range r.Points {
+		if point == nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Align returns a row for each valid from time in any of the series sorted by
+// time. Series should have one data point per time, e.g. by filtering them
+// and resampling them to the same interval. If a series has more than one
+// data point for a time the first is used.
+func Align(series ...Series) []Row {
+	rows := map[time.Time]*Row{}
+
+	for i, s := range series {
+		for j := range s {
+			validFrom := s[j].ValidFrom.UTC()
+			row, ok := rows[validFrom]
+			if !ok {
+				row = &Row{
+					ValidFrom: validFrom,
+					Points:    make([]*provider.CarbonIntensity, len(series)),
+				}
+				rows[validFrom] = row
+			}
+			if row.Points[i] == nil {
+				point := s[j]
+				row.Points[i] = &point
+			}
+		}
+	}
+
+	result := make([]Row, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, *row)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ValidFrom.Before(result[j].ValidFrom)
+	})
+
+	return result
+}
@@ -0,0 +1,120 @@
+package series
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/thegreenwebfoundation/grid-intensity-go/pkg/provider"
+)
+
+func point(providerName, location string, validFrom time.Time, value float64, estimated bool) provider.CarbonIntensity {
+	return provider.CarbonIntensity{
+		EmissionsType: provider.AverageEmissionsType,
+		MetricType:    provider.AbsoluteMetricType,
+		Provider:      providerName,
+		Location:      location,
+		Units:         provider.GramsCO2EPerkWh,
+		ValidFrom:     validFrom,
+		ValidTo:       validFrom.Add(time.Hour),
+		Value:         value,
+		IsEstimated:   estimated,
+	}
+}
+
+func Test_Series_FilterAndSort(t *testing.T) {
+	hour := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	s := Series{
+		point(provider.ElectricityMaps, "DE", hour.Add(time.Hour), 300, true),
+		point(provider.ElectricityMaps, "FR", hour, 50, false),
+		point(provider.ElectricityMaps, "DE", hour, 310, false),
+		point(provider.ENTSOE, "DE", hour, 320, false),
+	}
+
+	result := s.Filter(WithLocation("DE"), WithEstimated(false))
+	result.Sort()
+
+	expected := Series{
+		point(provider.ENTSOE, "DE", hour, 320, false),
+		point(provider.ElectricityMaps, "DE", hour, 310, false),
+	}
+	if !reflect.DeepEqual(expected, result) {
+		t.Errorf("want matching \n %s", cmp.Diff(result, expected))
+	}
+
+	latest, ok := s.Filter(WithProvider(provider.ElectricityMaps), Between(hour, hour.Add(2*time.Hour))).Latest()
+	if !ok || latest.Value != 300 {
+		t.Errorf("expected latest value 300 got %#v", latest)
+	}
+
+	if _, ok := (Series{}).Latest(); ok {
+		t.Errorf("expected no latest value for empty series")
+	}
+
+	byLocation := s.ByLocation()
+	if len(byLocation["DE"]) != 3 || len(byLocation["FR"]) != 1 {
+		t.Errorf("unexpected series by location %#v", byLocation)
+	}
+}
+
+func Test_Align(t *testing.T) {
+	hour := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	a := Series{
+		point(provider.ElectricityMaps, "DE", hour, 310, false),
+		point(provider.ElectricityMaps, "DE", hour.Add(time.Hour), 300, false),
+	}
+	b := Series{
+		point(provider.ENTSOE, "DE", hour.Add(time.Hour), 290, false),
+		point(provider.ENTSOE, "DE", hour.Add(2*time.Hour), 280, false),
+	}
+
+	rows := Align(a, b)
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 rows got %d", len(rows))
+	}
+
+	expected := []struct {
+		validFrom time.Time
+		values    []float64
+		complete  bool
+	}{
+		{
+			validFrom: hour,
+			values:    []float64{310, -1},
+		},
+		{
+			validFrom: hour.Add(time.Hour),
+			values:    []float64{300, 290},
+			complete:  true,
+		},
+		{
+			validFrom: hour.Add(2 * time.Hour),
+			values:    []float64{-1, 280},
+		},
+	}
+
+	for i, want := range expected {
+		row := rows[i]
+		if !row.ValidFrom.Equal(want.validFrom) {
+			t.Errorf("%d: expected time %s got %s", i, want.validFrom, row.ValidFrom)
+		}
+		if row.Complete() != want.complete {
+			t.Errorf("%d: expected complete %t got %t", i, want.complete, row.Complete())
+		}
+		for j, value := range want.values {
+			if value == -1 {
+				if row.Points[j] != nil {
+					t.Errorf("%d: expected no point for series %d got %#v", i, j, row.Points[j])
+				}
+				continue
+			}
+			if row.Points[j] == nil || row.Points[j].Value != value {
+				t.Errorf("%d: expected value %v for series %d got %#v", i, value, j, row.Points[j])
+			}
+		}
+	}
+}